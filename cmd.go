@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/goccy/go-json"
+	"github.com/spf13/cobra"
+
+	"github.com/saveblush/reraw-api/internal/core/cctx"
+	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+	"github.com/saveblush/reraw-api/internal/core/lifecycle"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/pgk/token"
+	"github.com/saveblush/reraw-api/internal/pgk/user"
+)
+
+// newRootCommand command หลัก ไม่ระบุ subcommand = serve
+func newRootCommand() *cobra.Command {
+	var configFile, addr string
+
+	root := &cobra.Command{
+		Use:           "reraw-api",
+		Short:         "NIP-05 identity server",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if configFile != "" {
+				config.SetConfigFile(configFile)
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := bootstrap(true)
+			if err != nil {
+				return err
+			}
+
+			return serve(addr)
+		},
+	}
+	root.PersistentFlags().StringVar(&configFile, "config", "", "config file (default ./configs/config.yml)")
+	root.Flags().StringVar(&addr, "addr", "", "http service address (default :APP.PORT)")
+
+	root.AddCommand(
+		newServeCommand(),
+		newExportCommand(),
+		newMigrateCommand(),
+		newUserCommand(),
+		newMaintenanceCommand(),
+		newTokenCommand(),
+		newConfigCommand(),
+	)
+
+	return root
+}
+
+// bootstrap อ่าน config และ init logger, return result
+// server = true จะ reset สถานะปิด/เปิดระบบ และ reload config เมื่อไฟล์เปลี่ยน
+func bootstrap(server bool) error {
+	// Init configuration
+	var err error
+	if server {
+		err = config.InitConfig()
+	} else {
+		err = config.LoadConfig()
+	}
+	if err != nil {
+		return fmt.Errorf("init configuration error: %w", err)
+	}
+
+	// Init logger from configuration
+	err = initLogger()
+	if err != nil {
+		return fmt.Errorf("init logger error: %w", err)
+	}
+
+	// Init return result
+	err = config.InitReturnResult()
+	if err != nil {
+		return fmt.Errorf("init return result error: %w", err)
+	}
+
+	return nil
+}
+
+// withDatabase init config และ database ก่อนเรียก fn แล้วปิด connection
+func withDatabase(fn func() error) error {
+	err := bootstrap(false)
+	if err != nil {
+		return err
+	}
+
+	err = initDatabase()
+	if err != nil {
+		return fmt.Errorf("init database error: %w", err)
+	}
+	defer closeDatabase()
+
+	return fn()
+}
+
+// printJSON print ผลลัพธ์เป็น json
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+func newServeCommand() *cobra.Command {
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the http server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := bootstrap(true)
+			if err != nil {
+				return err
+			}
+
+			return serve(addr)
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "", "http service address (default :APP.PORT)")
+
+	return cmd
+}
+
+func newExportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export-nostr",
+		Short: "Export nostr.json to EXPORT.OUTPUT_PATHS",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDatabase(func() error {
+				paths, err := user.Export(sql.Database, config.CF.Export.OutputPaths)
+				if err != nil {
+					return fmt.Errorf("export nostr.json error: %w", err)
+				}
+				logger.Log.Infof("exported nostr.json to %v", paths)
+
+				return nil
+			})
+		},
+	}
+}
+
+func newMaintenanceCommand() *cobra.Command {
+	var message string
+
+	cmd := &cobra.Command{
+		Use:       "maintenance on|off",
+		Short:     "Turn maintenance mode on or off",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"on", "off"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := bootstrap(false)
+			if err != nil {
+				return err
+			}
+
+			// maintenance on = ปิดระบบ
+			status := config.AvailableStatusOnline
+			if args[0] == "on" {
+				status = config.AvailableStatusOffline
+			}
+
+			err = config.CF.SetConfigAvailableStatus(status)
+			if err != nil {
+				return err
+			}
+			err = config.CF.SetConfigAvailableDescription(message)
+			if err != nil {
+				return err
+			}
+			logger.Log.Infof("system is %s", status)
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&message, "message", "", "html shown on the maintenance page")
+
+	return cmd
+}
+
+func newTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage access tokens",
+	}
+
+	var sub, role string
+	issue := &cobra.Command{
+		Use:   "issue",
+		Short: "Issue an access and refresh token",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := bootstrap(false)
+			if err != nil {
+				return err
+			}
+
+			res, err := token.NewService().Create(&cctx.Context{}, &token.Request{
+				UserID:    sub,
+				UserLevel: role,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printJSON(res)
+		},
+	}
+	issue.Flags().StringVar(&sub, "sub", "", "token subject")
+	issue.Flags().StringVar(&role, "role", "", "token role")
+	_ = issue.MarkFlagRequired("sub")
+	_ = issue.MarkFlagRequired("role")
+	cmd.AddCommand(issue)
+
+	return cmd
+}
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect configuration",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "check",
+		Short: "Validate config files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := bootstrap(false)
+			if err != nil {
+				return err
+			}
+
+			err = checkConfig()
+			if err != nil {
+				return err
+			}
+			fmt.Println("config ok")
+
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// checkConfig ตรวจค่า config ที่อ่านได้แต่ใช้งานไม่ได้
+func checkConfig() error {
+	var errs []error
+	cf := config.CF
+
+	if cf.App.Port <= 0 || cf.App.Port > 65535 {
+		errs = append(errs, fmt.Errorf("APP.PORT: invalid port %d", cf.App.Port))
+	}
+	if cf.JWT.AccessSecretKey == "" || cf.JWT.RefreshSecretKey == "" {
+		errs = append(errs, errors.New("JWT: ACCESS_SECRET_KEY and REFRESH_SECRET_KEY are required"))
+	}
+
+	drivers := []string{sql.PostgresDriver, sql.MysqlDriver, sql.SqliteDriver}
+	if !slices.Contains(drivers, cf.Database.RelaySQL.DriverName) {
+		errs = append(errs, fmt.Errorf("DATABASE.RELAY_SQL.DRIVER_NAME: unknown driver %q, expected one of %v", cf.Database.RelaySQL.DriverName, drivers))
+	}
+
+	drivers = []string{"", cache.DriverRedis, cache.DriverMemory, cache.DriverNoop}
+	if !slices.Contains(drivers, cf.Cache.Driver) {
+		errs = append(errs, fmt.Errorf("CACHE.DRIVER: unknown driver %q", cf.Cache.Driver))
+	}
+	modes := []string{"", cache.ModeStandalone, cache.ModeSentinel, cache.ModeCluster}
+	if !slices.Contains(modes, cf.Cache.Redis.Mode) {
+		errs = append(errs, fmt.Errorf("CACHE.REDIS.MODE: unknown mode %q", cf.Cache.Redis.Mode))
+	}
+	if cf.Cache.Redis.Mode == cache.ModeSentinel && cf.Cache.Redis.MasterName == "" {
+		errs = append(errs, errors.New("CACHE.REDIS.MASTER_NAME: required in sentinel mode"))
+	}
+	if cf.Cache.ExprieTime.Jitter < 0 || cf.Cache.ExprieTime.Jitter > cache.MaxJitterRatio {
+		errs = append(errs, fmt.Errorf("CACHE.EXPIRE_TIME.JITTER: %v is out of range 0-%v", cf.Cache.ExprieTime.Jitter, cache.MaxJitterRatio))
+	}
+
+	if cf.Metrics.Enable && cf.Metrics.Port > 0 && cf.HTTPServer.Prefork {
+		errs = append(errs, errMetricsPortPrefork)
+	}
+
+	if cf.Export.Enable && len(cf.Export.OutputPaths) == 0 {
+		errs = append(errs, errors.New("EXPORT.OUTPUT_PATHS: required when EXPORT.ENABLE is true"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// openCache เปิด cache สำหรับ command ที่แก้ข้อมูล
+// เพื่อลบ cache ของ instance ที่ทำงานอยู่
+func openCache() (cache.Service, func(), error) {
+	cacheService, err := initCache()
+	if err != nil {
+		return nil, nil, fmt.Errorf("init cache error: %w", err)
+	}
+
+	return cacheService, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultCleanupTimeout)
+		defer cancel()
+		lifecycle.Stop(ctx)
+		_ = cacheService.Close()
+	}, nil
+}
+
+// exportAfterChange export nostr.json ทันที
+// command line จบก่อน TriggerExport ที่ debounce ไว้จะทำงาน
+func exportAfterChange(ctx context.Context) {
+	if !config.CF.Export.Enable {
+		return
+	}
+
+	_, err := user.Export(sql.Database.WithContext(ctx), config.CF.Export.OutputPaths)
+	if err != nil {
+		logger.Log.Errorf("export nostr.json error: %s", err)
+	}
+}
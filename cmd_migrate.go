@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database schema migrations",
+	}
+
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDatabase(func() error {
+				done, err := sql.MigrateUp(context.Background(), sql.Database)
+				if err != nil {
+					return err
+				}
+				logger.Log.Infof("applied %d migrations", len(done))
+
+				return nil
+			})
+		},
+	}
+
+	var steps int
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the latest migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if steps <= 0 {
+				return fmt.Errorf("invalid steps: %d", steps)
+			}
+
+			return withDatabase(func() error {
+				done, err := sql.MigrateDown(context.Background(), sql.Database, steps)
+				if err != nil {
+					return err
+				}
+				logger.Log.Infof("rolled back %d migrations", len(done))
+
+				return nil
+			})
+		},
+	}
+	down.Flags().IntVar(&steps, "steps", 1, "number of migrations to roll back")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Show applied and pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDatabase(func() error {
+				list, err := sql.MigrateStatus(context.Background(), sql.Database)
+				if err != nil {
+					return err
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+				for _, m := range list {
+					appliedAt := "pending"
+					if m.Applied {
+						appliedAt = m.AppliedAt.Format(time.RFC3339)
+					}
+					fmt.Fprintf(w, "%04d\t%s\t%s\n", m.Version, m.Name, appliedAt)
+				}
+
+				return w.Flush()
+			})
+		},
+	}
+
+	cmd.AddCommand(up, down, status)
+
+	return cmd
+}
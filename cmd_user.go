@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/pgk/user"
+)
+
+func newUserCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage NIP-05 users",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDatabase(func() error {
+				users, err := user.NewAdmin(sql.Database, nil).List(context.Background())
+				if err != nil {
+					return err
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "NAME\tDOMAIN\tPUBKEY\tLIGHTNING\tRELAYS")
+				for _, u := range users {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", u.Name, u.Domain, u.Pubkey, u.LightningURL, strings.Join(u.Relays, ","))
+				}
+
+				return w.Flush()
+			})
+		},
+	}
+
+	var lightning, domain string
+	add := &cobra.Command{
+		Use:   "add <name> <pubkey>",
+		Short: "Add a user",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withUserAdmin(func(ctx context.Context, admin *user.Admin) error {
+				u, err := admin.Add(ctx, args[0], args[1], lightning, domain)
+				if err != nil {
+					return err
+				}
+				logger.Log.Infof("user %s added", u.Name)
+
+				return nil
+			})
+		},
+	}
+	add.Flags().StringVar(&lightning, "lightning", "", "lightning address (name@domain)")
+	add.Flags().StringVar(&domain, "domain", "", "nip-05 domain for per-domain export (default all domains)")
+
+	rm := &cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withUserAdmin(func(ctx context.Context, admin *user.Admin) error {
+				u, err := admin.Remove(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				logger.Log.Infof("user %s removed", u.Name)
+
+				return nil
+			})
+		},
+	}
+
+	setLightning := &cobra.Command{
+		Use:   "set-lightning <name> [address]",
+		Short: "Set or clear the lightning address of a user",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address := ""
+			if len(args) > 1 {
+				address = args[1]
+			}
+
+			return withUserAdmin(func(ctx context.Context, admin *user.Admin) error {
+				u, err := admin.SetLightning(ctx, args[0], address)
+				if err != nil {
+					return err
+				}
+				logger.Log.Infof("user %s lightning address set to %q", u.Name, u.LightningURL)
+
+				return nil
+			})
+		},
+	}
+
+	setRelays := &cobra.Command{
+		Use:   "set-relays <name> [relay...]",
+		Short: "Set the relays of a user (none = use APP.LAZY_RELAYS)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withUserAdmin(func(ctx context.Context, admin *user.Admin) error {
+				u, err := admin.SetRelays(ctx, args[0], args[1:])
+				if err != nil {
+					return err
+				}
+				logger.Log.Infof("user %s relays set to %v", u.Name, u.Relays)
+
+				return nil
+			})
+		},
+	}
+
+	cmd.AddCommand(list, add, rm, setLightning, setRelays)
+
+	return cmd
+}
+
+// withUserAdmin init database และ cache ก่อนแก้ข้อมูล user
+// แล้ว export nostr.json ใหม่เมื่อสำเร็จ
+func withUserAdmin(fn func(ctx context.Context, admin *user.Admin) error) error {
+	return withDatabase(func() error {
+		cacheService, closeCache, err := openCache()
+		if err != nil {
+			return err
+		}
+		defer closeCache()
+
+		ctx := context.Background()
+		err = fn(ctx, user.NewAdmin(sql.Database, cacheService))
+		if err != nil {
+			return err
+		}
+		exportAfterChange(ctx)
+
+		return nil
+	})
+}
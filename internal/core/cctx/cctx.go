@@ -20,6 +20,7 @@ const (
 	LangKey            = "lang"
 	UserKey            = "user"
 	ParametersKey      = "parameters"
+	LoggerKey          = "logger"
 )
 
 // Context context
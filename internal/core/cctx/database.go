@@ -6,7 +6,21 @@ import (
 	"github.com/saveblush/reraw-api/internal/core/connection/sql"
 )
 
+const primaryKey = "database:primary"
+
 // GetDatabase get connection database
+// query อ่านจะไป replica เว้นแต่เรียก ForcePrimary ไว้
 func (c *Context) GetDatabase() *gorm.DB {
-	return sql.Database
+	db := sql.Database.WithContext(c.Context())
+	if primary, _ := c.Locals(primaryKey).(bool); primary {
+		return sql.Primary(db)
+	}
+
+	return db
+}
+
+// ForcePrimary ให้ query ที่เหลือของ request อ่านจาก primary
+// เรียกหลังเขียนข้อมูล เพื่อไม่ให้อ่านได้ข้อมูลเก่าจาก replica
+func (c *Context) ForcePrimary() {
+	c.Locals(primaryKey, true)
 }
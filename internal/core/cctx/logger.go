@@ -0,0 +1,31 @@
+package cctx
+
+import (
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"go.uber.org/zap"
+
+	"github.com/saveblush/reraw-api/internal/core/tracing"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+// Logger get logger ของ request
+// แนบ request id, trace id, user และ route ไว้ทุกบรรทัด
+func (c *Context) Logger() *zap.SugaredLogger {
+	if c == nil || c.Ctx == nil {
+		return logger.Log
+	}
+
+	if l, ok := c.Locals(LoggerKey).(*zap.SugaredLogger); ok {
+		return l
+	}
+
+	l := logger.Log.With(
+		zap.String("request_id", requestid.FromContext(c.Ctx)),
+		zap.String("trace_id", tracing.TraceID(c.Context())),
+		zap.String("subject", c.GetUserID()),
+		zap.String("route", c.Route().Path),
+	)
+	c.Locals(LoggerKey, l)
+
+	return l
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/goccy/go-json"
 	"github.com/spf13/viper"
 
+	"github.com/saveblush/reraw-api/internal/core/lifecycle"
 	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 )
 
@@ -21,6 +24,12 @@ var (
 )
 
 var (
+	changeMu    sync.Mutex
+	changeHooks []func()
+)
+
+var (
+	configFile                         = "" // ว่าง = configs/config.yml
 	filePath                           = "./configs"
 	fileExtension                      = "yml"
 	fileNameConfig                     = "config"
@@ -60,6 +69,18 @@ type DatabaseConfig struct {
 	MaxIdleConns int           `mapstructure:"MAX_IDLE_CONNS"`
 	MaxOpenConns int           `mapstructure:"MAX_OPEN_CONNS"`
 	MaxLifetime  time.Duration `mapstructure:"MAX_LIFE_TIME"`
+
+	// read replica
+	Replicas             []DatabaseReplicaConfig `mapstructure:"REPLICAS"`
+	ReplicaCheckInterval time.Duration           `mapstructure:"REPLICA_CHECK_INTERVAL"`
+}
+
+// DatabaseReplicaConfig read replica ค่าที่ว่างจะใช้ของ primary
+type DatabaseReplicaConfig struct {
+	Host     string `mapstructure:"HOST"`
+	Port     int    `mapstructure:"PORT"`
+	Username string `mapstructure:"USERNAME"`
+	Password string `mapstructure:"PASSWORD"`
 }
 
 type UserPassConfig struct {
@@ -86,8 +107,21 @@ type Configs struct {
 		LazyRelays      []string         `mapstructure:"LAZY_RELAYS"`
 	} `mapstructure:"APP"`
 
+	Startup struct {
+		Retries           int           `mapstructure:"RETRIES"`
+		Backoff           time.Duration `mapstructure:"BACKOFF"`
+		MaxBackoff        time.Duration `mapstructure:"MAX_BACKOFF"`
+		Degraded          bool          `mapstructure:"DEGRADED"` // เปิดระบบได้แม้ db/cache ยังไม่พร้อม
+		ReconnectInterval time.Duration `mapstructure:"RECONNECT_INTERVAL"`
+	} `mapstructure:"STARTUP"`
+
 	HTTPServer struct {
-		Prefork   bool `mapstructure:"PREFORK"`
+		Prefork  bool `mapstructure:"PREFORK"`
+		Shutdown struct {
+			PreStopDelay   time.Duration `mapstructure:"PRE_STOP_DELAY"` // รอให้ load balancer เห็นว่า readiness fail
+			DrainTimeout   time.Duration `mapstructure:"DRAIN_TIMEOUT"`  // รอ request ที่ค้างอยู่
+			CleanupTimeout time.Duration `mapstructure:"CLEANUP_TIMEOUT"`
+		} `mapstructure:"SHUTDOWN"`
 		RateLimit struct {
 			Max        int           `mapstructure:"MAX"`
 			Expiration time.Duration `mapstructure:"EXPIRATION"`
@@ -118,44 +152,147 @@ type Configs struct {
 	} `mapstructure:"JWT"`
 
 	Database struct {
-		RelaySQL DatabaseConfig `mapstructure:"RELAY_SQL"`
+		AutoMigrate bool           `mapstructure:"AUTO_MIGRATE"` // migrate up ตอนเริ่มระบบ
+		RelaySQL    DatabaseConfig `mapstructure:"RELAY_SQL"`
 	} `mapstructure:"DATABASE"`
 
 	Cache struct {
+		Driver     string `mapstructure:"DRIVER"` // redis, memory, noop
 		ExprieTime struct {
-			UserInfo time.Duration `mapstructure:"USERINFO"`
+			UserInfo  time.Duration `mapstructure:"USERINFO"`
+			WellKnown time.Duration `mapstructure:"WELLKNOWN"`
+			NotFound  time.Duration `mapstructure:"NOT_FOUND"` // negative cache, 0 = ปิด
+			Jitter    float64       `mapstructure:"JITTER"`    // สัดส่วนสุ่ม expire time (0-0.5)
+			Coalesce  bool          `mapstructure:"COALESCE"`  // รวม request ที่ miss key เดียวกันเป็นครั้งเดียว
 		} `mapstructure:"EXPIRE_TIME"`
+		Local struct {
+			Enable  bool          `mapstructure:"ENABLE"`
+			Size    int           `mapstructure:"SIZE"`
+			TTL     time.Duration `mapstructure:"TTL"`
+			Channel string        `mapstructure:"CHANNEL"` // redis pub/sub channel สำหรับ invalidate
+		} `mapstructure:"LOCAL"`
+		Memory struct {
+			Size int `mapstructure:"SIZE"`
+		} `mapstructure:"MEMORY"`
 		Redis struct {
-			Host     string `mapstructure:"HOST"`
-			Port     int    `mapstructure:"PORT"`
-			Password string `mapstructure:"PASSWORD"`
-			DB       int    `mapstructure:"DB"`
+			Mode             string        `mapstructure:"MODE"` // standalone, sentinel, cluster
+			Host             string        `mapstructure:"HOST"`
+			Port             int           `mapstructure:"PORT"`
+			Addrs            []string      `mapstructure:"ADDRS"`
+			MasterName       string        `mapstructure:"MASTER_NAME"`
+			Username         string        `mapstructure:"USERNAME"`
+			Password         string        `mapstructure:"PASSWORD"`
+			SentinelUsername string        `mapstructure:"SENTINEL_USERNAME"`
+			SentinelPassword string        `mapstructure:"SENTINEL_PASSWORD"`
+			DB               int           `mapstructure:"DB"`
+			PoolSize         int           `mapstructure:"POOL_SIZE"`
+			MinIdleConns     int           `mapstructure:"MIN_IDLE_CONNS"`
+			DialTimeout      time.Duration `mapstructure:"DIAL_TIMEOUT"`
+			ReadTimeout      time.Duration `mapstructure:"READ_TIMEOUT"`
+			WriteTimeout     time.Duration `mapstructure:"WRITE_TIMEOUT"`
+			KeyPrefix        string        `mapstructure:"KEY_PREFIX"`
+			TLS              struct {
+				Enable             bool   `mapstructure:"ENABLE"`
+				CAFile             string `mapstructure:"CA_FILE"`
+				CertFile           string `mapstructure:"CERT_FILE"`
+				KeyFile            string `mapstructure:"KEY_FILE"`
+				ServerName         string `mapstructure:"SERVER_NAME"`
+				InsecureSkipVerify bool   `mapstructure:"INSECURE_SKIP_VERIFY"`
+			} `mapstructure:"TLS"`
 		} `mapstructure:"REDIS"`
 	} `mapstructure:"CACHE"`
 
+	Log struct {
+		Level        string   `mapstructure:"LEVEL"`
+		Encoding     string   `mapstructure:"ENCODING"`      // json, console
+		Outputs      []string `mapstructure:"OUTPUTS"`       // stdout, stderr, path ไฟล์
+		ErrorOutputs []string `mapstructure:"ERROR_OUTPUTS"` // stdout, stderr, path ไฟล์
+		Rotation     struct {
+			MaxSize    int  `mapstructure:"MAX_SIZE"` // MB
+			MaxBackups int  `mapstructure:"MAX_BACKUPS"`
+			MaxAge     int  `mapstructure:"MAX_AGE"` // days
+			Compress   bool `mapstructure:"COMPRESS"`
+		} `mapstructure:"ROTATION"`
+	} `mapstructure:"LOG"`
+
+	AccessLog struct {
+		Enable        bool     `mapstructure:"ENABLE"`
+		IncludePaths  []string `mapstructure:"INCLUDE_PATHS"` // prefix, ว่าง = ทุก path
+		ExcludePaths  []string `mapstructure:"EXCLUDE_PATHS"` // prefix
+		Headers       bool     `mapstructure:"HEADERS"`
+		RequestBody   bool     `mapstructure:"REQUEST_BODY"`
+		ResponseBody  bool     `mapstructure:"RESPONSE_BODY"`
+		MaxBodySize   int      `mapstructure:"MAX_BODY_SIZE"` // bytes, 0 = 4KiB, -1 = ไม่จำกัด
+		RedactFields  []string `mapstructure:"REDACT_FIELDS"`
+		RedactHeaders []string `mapstructure:"REDACT_HEADERS"`
+	} `mapstructure:"ACCESS_LOG"`
+
+	HealthCheck struct {
+		Timeout  time.Duration `mapstructure:"TIMEOUT"`
+		CacheTTL time.Duration `mapstructure:"CACHE_TTL"`
+		Relays   bool          `mapstructure:"RELAYS"` // เช็ค LAZY_RELAYS ด้วย
+	} `mapstructure:"HEALTHCHECK"`
+
+	Metrics struct {
+		Enable bool   `mapstructure:"ENABLE"`
+		Path   string `mapstructure:"PATH"`
+		Port   int    `mapstructure:"PORT"` // 0 = ใช้ port เดียวกับ app (ต้อง auth admin)
+	} `mapstructure:"METRICS"`
+
+	Tracing struct {
+		Enable      bool     `mapstructure:"ENABLE"`
+		Exporter    string   `mapstructure:"EXPORTER"` // otlp, stdout, file, none
+		Endpoint    string   `mapstructure:"ENDPOINT"`
+		Insecure    bool     `mapstructure:"INSECURE"`
+		FilePath    string   `mapstructure:"FILE_PATH"`
+		SampleRatio *float64 `mapstructure:"SAMPLE_RATIO"` // ไม่กำหนด = 1, 0 = ไม่เก็บ
+	} `mapstructure:"TRACING"`
+
+	Degraded struct {
+		StaleTTL         time.Duration `mapstructure:"STALE_TTL"` // 0 = ไม่หมดอายุ
+		SnapshotPath     string        `mapstructure:"SNAPSHOT_PATH"`
+		SnapshotInterval time.Duration `mapstructure:"SNAPSHOT_INTERVAL"` // 0 = ไม่เขียน snapshot
+	} `mapstructure:"DEGRADED"`
+
+	Jobs struct {
+		LockTTL     time.Duration `mapstructure:"LOCK_TTL"`
+		ElectionTTL time.Duration `mapstructure:"ELECTION_TTL"`
+	} `mapstructure:"JOBS"`
+
+	WellKnown struct {
+		CacheControl struct {
+			MaxAge               time.Duration `mapstructure:"MAX_AGE"`
+			StaleWhileRevalidate time.Duration `mapstructure:"STALE_WHILE_REVALIDATE"`
+		} `mapstructure:"CACHE_CONTROL"`
+		Bulk struct {
+			Enable   bool `mapstructure:"ENABLE"`    // ไม่ส่ง name จะตอบรายชื่อทั้งหมด
+			MaxUsers int  `mapstructure:"MAX_USERS"` // 0 = ไม่จำกัด
+		} `mapstructure:"BULK"`
+	} `mapstructure:"WELL_KNOWN"`
+
+	Export struct {
+		Enable      bool          `mapstructure:"ENABLE"`       // export ใหม่อัตโนมัติเมื่อ user เปลี่ยน
+		OutputPaths []string      `mapstructure:"OUTPUT_PATHS"` // {domain} = แยกไฟล์ต่อ domain
+		Interval    time.Duration `mapstructure:"INTERVAL"`     // export ตามรอบ สำหรับข้อมูลที่แก้ตรงใน database, 0 = ไม่ทำ
+	} `mapstructure:"EXPORT"`
+
 	HTMLTemplate struct {
 		SystemMaintenance string `mapstructure:"SYSTEM_MAINTENANCE"`
 	} `mapstructure:"HTML_TEMPLATE"`
 }
 
-// InitConfig init config
-func InitConfig() error {
-	v := viper.New()
-	v.AddConfigPath(filePath)
-	v.SetConfigName(fileNameConfig)
-	v.SetConfigType(fileExtension)
-	v.AutomaticEnv()
-
-	// แปลง _ underscore เป็น . dot
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-
-	if err := v.ReadInConfig(); err != nil {
-		logger.Log.Errorf("read config file error: %s", err)
-		return err
-	}
+// SetConfigFile ใช้ config file ตาม path ที่กำหนด
+// ไฟล์อื่น (return_result, สถานะปิด/เปิดระบบ) อ่านจาก directory เดียวกัน
+func SetConfigFile(path string) {
+	configFile = path
+	filePath = filepath.Dir(path)
+}
 
-	if err := bindingConfig(v, CF); err != nil {
-		logger.Log.Errorf("binding config error: %s", err)
+// InitConfig init config สำหรับ server
+// reset สถานะปิด/เปิดระบบ และ reload เมื่อไฟล์เปลี่ยน
+func InitConfig() error {
+	v, err := readConfig()
+	if err != nil {
 		return err
 	}
 
@@ -166,16 +303,78 @@ func InitConfig() error {
 	}
 
 	v.OnConfigChange(func(e fsnotify.Event) {
+		if lifecycle.Stopping() {
+			return
+		}
 		logger.Log.Infof("config file changed: %s", e.Name)
 		if err := v.Unmarshal(CF); err != nil {
 			logger.Log.Errorf("binding config error: %s", err)
+			return
 		}
+		notifyChange()
 	})
 	v.WatchConfig()
 
 	return nil
 }
 
+// LoadConfig อ่าน config อย่างเดียว สำหรับ command line
+// ไม่แตะสถานะปิด/เปิดระบบของ server ที่ทำงานอยู่
+func LoadConfig() error {
+	_, err := readConfig()
+	return err
+}
+
+// readConfig อ่าน config file แล้ว binding ลง CF
+func readConfig() (*viper.Viper, error) {
+	v := viper.New()
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+	} else {
+		v.AddConfigPath(filePath)
+		v.SetConfigName(fileNameConfig)
+		v.SetConfigType(fileExtension)
+	}
+	v.AutomaticEnv()
+
+	// แปลง _ underscore เป็น . dot
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	// config เดิมที่ไม่มี ACCESS_LOG ยังเก็บ access log ทุก request เหมือนเดิม
+	v.SetDefault("ACCESS_LOG.ENABLE", true)
+
+	if err := v.ReadInConfig(); err != nil {
+		logger.Log.Errorf("read config file error: %s", err)
+		return nil, err
+	}
+
+	if err := bindingConfig(v, CF); err != nil {
+		logger.Log.Errorf("binding config error: %s", err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// OnChange register callback ที่จะถูกเรียกหลัง reload config file
+func OnChange(fn func()) {
+	changeMu.Lock()
+	defer changeMu.Unlock()
+
+	changeHooks = append(changeHooks, fn)
+}
+
+// notifyChange เรียก callback ทั้งหมดหลัง reload config
+func notifyChange() {
+	changeMu.Lock()
+	hooks := changeHooks
+	changeMu.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
 // bindingConfig binding config
 func bindingConfig(vp *viper.Viper, cf *Configs) error {
 	if err := vp.Unmarshal(&cf); err != nil {
@@ -263,6 +462,9 @@ func initConfigAvailable() error {
 	CF.App.AvailableStatus = cf.Status
 
 	v.OnConfigChange(func(e fsnotify.Event) {
+		if lifecycle.Stopping() {
+			return
+		}
 		logger.Log.Infof("config file changed: %s", e.Name)
 		if err := v.Unmarshal(cf); err != nil {
 			logger.Log.Errorf("binding config error: %s", err)
@@ -305,7 +507,7 @@ func (cf *Configs) SetConfigAvailableDescription(body string) error {
 // ReadConfigAvailableDescription read config available description
 // อ่าน config html ใช้แสดงเมื่อปิดระบบ
 func (cf *Configs) ReadConfigAvailableDescription() (string, error) {
-	d, err := os.ReadFile(fmt.Sprintf("./%s/%s", filePath, fileNameConfigAvailableDescription))
+	d, err := os.ReadFile(fmt.Sprintf("%s/%s", filePath, fileNameConfigAvailableDescription))
 	if err != nil {
 		return "", err
 	}
@@ -6,6 +6,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/spf13/viper"
 
+	"github.com/saveblush/reraw-api/internal/core/lifecycle"
 	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 )
 
@@ -158,6 +159,9 @@ func InitReturnResult() error {
 	}
 
 	v.OnConfigChange(func(e fsnotify.Event) {
+		if lifecycle.Stopping() {
+			return
+		}
 		logger.Log.Infof("config file changed: %s", e.Name)
 		if err := v.Unmarshal(CF); err != nil {
 			logger.Log.Errorf("binding config error: %s", err)
@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMiss key ไม่มีใน cache (หรือหมดอายุแล้ว)
+var ErrMiss = errors.New("cache: key does not exist")
+
+// Item ค่าที่จะเขียนลง cache แบบหลาย key
+type Item struct {
+	Key   string
+	Value []byte
+	TTL   time.Duration // <= 0 ไม่หมดอายุ
+	Tags  []string
+}
+
+// Store low-level api ที่ทุก driver ต้อง implement
+// ทุก method รับ context ของ request และคืน ErrMiss เมื่อไม่เจอ key
+type Store interface {
+	Ping(ctx context.Context) error
+	GetBytes(ctx context.Context, key string) ([]byte, error)
+	MGetBytes(ctx context.Context, keys ...string) ([][]byte, error) // key ที่ไม่เจอเป็น nil
+	SetBytes(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	MSetBytes(ctx context.Context, items ...Item) error
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error)                    // ลบเมื่อค่าตรงกัน
+	CompareAndExpire(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) // ต่ออายุเมื่อค่าตรงกัน
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	TTL(ctx context.Context, key string) (time.Duration, error) // 0 = ไม่หมดอายุ
+	Delete(ctx context.Context, keys ...string) error
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	InvalidateTag(ctx context.Context, tags ...string) error // ลบทุก key ที่ผูกกับ tag
+	Close() error
+}
+
+// Pooler cache ที่มี connection pool (redis)
+type Pooler interface {
+	PoolStats() *redis.PoolStats
+}
+
+// Get get ค่าจาก cache แล้ว decode เป็น T
+func Get[T any](ctx context.Context, s Store, key string) (T, error) {
+	var value T
+	data, err := s.GetBytes(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// Set encode value แล้วเก็บลง cache
+func Set[T any](ctx context.Context, s Store, key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.SetBytes(ctx, key, data, ttl)
+}
+
+// MGet get หลาย key ในครั้งเดียว
+// key ที่ไม่เจอจะไม่อยู่ใน map
+func MGet[T any](ctx context.Context, s Store, keys ...string) (map[string]T, error) {
+	res := make(map[string]T, len(keys))
+	if len(keys) == 0 {
+		return res, nil
+	}
+
+	values, err := s.MGetBytes(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, data := range values {
+		if data == nil {
+			continue
+		}
+
+		var value T
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		res[keys[i]] = value
+	}
+
+	return res, nil
+}
+
+// MSet เก็บหลาย key ใน round trip เดียว ด้วย expire time เดียวกัน
+func MSet[T any](ctx context.Context, s Store, values map[string]T, ttl time.Duration) error {
+	items := make([]Item, 0, len(values))
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		items = append(items, Item{Key: key, Value: data, TTL: ttl})
+	}
+
+	return s.MSetBytes(ctx, items...)
+}
+
+// SetWithTags encode value แล้วเก็บลง cache พร้อมผูก tag
+// ใช้ InvalidateTag ลบทุก key ของ tag ในครั้งเดียว
+func SetWithTags[T any](ctx context.Context, s Store, key string, value T, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.SetWithTags(ctx, key, data, ttl, tags...)
+}
+
+// Service service interface
+// api เดิมที่ผูก context ไว้กับ service ใช้ WithContext ส่ง context ของ request
+type Service interface {
+	WithContext(ctx context.Context) Service
+	Store() Store
+	Ping(ctx context.Context) error
+	Set(key string, value interface{}, expiredTime time.Duration) error
+	Get(key string, value interface{}) error
+	SetBytes(key string, value []byte, expiredTime time.Duration) error
+	GetBytes(key string) ([]byte, error)
+	GetKeys(pattern string) ([]string, error)
+	Delete(key string) error
+	SetWithTags(key string, value interface{}, expiredTime time.Duration, tags ...string) error
+	InvalidateTag(tag string) error
+	Close() error
+}
+
+// service adapter จาก Service ไปยัง Store
+type service struct {
+	store Store
+	ctx   context.Context
+}
+
+// New new service จาก store
+func New(store Store) Service {
+	return &service{
+		store: store,
+		ctx:   context.Background(),
+	}
+}
+
+// WithContext with context
+// ใช้ส่ง context ของ request ไปยัง cache
+func (s *service) WithContext(ctx context.Context) Service {
+	return &service{
+		store: s.store,
+		ctx:   ctx,
+	}
+}
+
+// Store store
+func (s *service) Store() Store {
+	return s.store
+}
+
+func (s *service) Ping(ctx context.Context) error {
+	return s.store.Ping(ctx)
+}
+
+func (s *service) Set(key string, value interface{}, expiredTime time.Duration) error {
+	return Set(s.ctx, s.store, key, &value, expiredTime)
+}
+
+func (s *service) Get(key string, value interface{}) error {
+	data, err := s.store.GetBytes(s.ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &value)
+}
+
+// SetBytes set raw bytes ไม่ผ่าน json
+func (s *service) SetBytes(key string, value []byte, expiredTime time.Duration) error {
+	return s.store.SetBytes(s.ctx, key, value, expiredTime)
+}
+
+// GetBytes get raw bytes ไม่ผ่าน json
+func (s *service) GetBytes(key string) ([]byte, error) {
+	return s.store.GetBytes(s.ctx, key)
+}
+
+func (s *service) GetKeys(pattern string) ([]string, error) {
+	return s.store.Keys(s.ctx, pattern)
+}
+
+func (s *service) Delete(key string) error {
+	return s.store.Delete(s.ctx, key)
+}
+
+func (s *service) SetWithTags(key string, value interface{}, expiredTime time.Duration, tags ...string) error {
+	return SetWithTags(s.ctx, s.store, key, &value, expiredTime, tags...)
+}
+
+func (s *service) InvalidateTag(tag string) error {
+	return s.store.InvalidateTag(s.ctx, tag)
+}
+
+// Close close connection
+func (s *service) Close() error {
+	return s.store.Close()
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/core/utils/retry"
+)
+
+var (
+	DriverRedis  = "redis"
+	DriverMemory = "memory"
+	DriverNoop   = "noop"
+)
+
+// Driver สร้าง cache store จาก configuration
+type Driver func(cf *Configuration) (Store, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{}
+)
+
+func init() {
+	Register(DriverRedis, newRedis)
+	Register(DriverMemory, newMemory)
+	Register(DriverNoop, newNoop)
+}
+
+// Register register cache driver
+// driver ชื่อซ้ำจะถูกแทนที่
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	drivers[name] = driver
+}
+
+// Open open cache ตาม driver ที่กำหนด (default redis) โดยไม่ ping
+func Open(cf *Configuration) (Service, error) {
+	name := cf.Driver
+	if name == "" {
+		name = DriverRedis
+	}
+
+	driversMu.RLock()
+	driver, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown cache driver %q", name)
+	}
+
+	store, err := driver(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(store), nil
+}
+
+// Init open cache และ ping ซ้ำตาม retry policy จนกว่าจะเชื่อมต่อได้
+func Init(cf *Configuration) (Service, error) {
+	s, err := Open(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	err = retry.Do(context.Background(), cf.Retry, func(attempt int) error {
+		err := s.Ping(context.Background())
+		if err != nil {
+			logger.Log.Warnf("ping cache error (attempt %d/%d): %s", attempt, cf.Retry.Attempts, err)
+		}
+		return err
+	})
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,306 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/saveblush/reraw-api/internal/core/metrics"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+var (
+	defaultLocalSize    = 10000
+	defaultLocalTTL     = 30 * time.Second
+	defaultLocalChannel = "cache-invalidate"
+
+	// invalidateSeparator แยก instance id กับ key ใน message pub/sub
+	invalidateSeparator = "|"
+)
+
+// LocalConfiguration config in-process cache
+type LocalConfiguration struct {
+	Size    int
+	TTL     time.Duration
+	Channel string
+}
+
+// localCache lru ใน process พร้อม channel แจ้ง invalidate ข้าม instance
+type localCache struct {
+	client     redis.UniversalClient
+	lru        *expirable.LRU[string, []byte]
+	channel    string
+	instanceID string
+}
+
+// NewLocal new in-process cache หน้า redis
+// subscribe pub/sub เพื่อลบ key พร้อมกันทุก instance (รวม prefork child)
+func NewLocal(remote Service, cf *LocalConfiguration) (Service, func(ctx context.Context) error, error) {
+	conn, ok := remote.Store().(*connection)
+	if !ok {
+		return nil, nil, errors.New("local cache requires redis driver")
+	}
+
+	size := cf.Size
+	if size <= 0 {
+		size = defaultLocalSize
+	}
+	ttl := cf.TTL
+	if ttl <= 0 {
+		ttl = defaultLocalTTL
+	}
+	channel := cf.Channel
+	if channel == "" {
+		channel = defaultLocalChannel
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, nil, err
+	}
+
+	lc := &localCache{
+		client:     conn.client,
+		lru:        expirable.NewLRU[string, []byte](size, nil, ttl),
+		channel:    conn.key(channel),
+		instanceID: hex.EncodeToString(id),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pubsub := lc.client.Subscribe(ctx, lc.channel)
+	go lc.listen(ctx, pubsub.Channel())
+
+	stop := func(context.Context) error {
+		cancel()
+		return pubsub.Close()
+	}
+
+	return New(&layered{local: lc, remote: conn}), stop, nil
+}
+
+// listen รับ message invalidate จาก instance อื่น
+func (lc *localCache) listen(ctx context.Context, ch <-chan *redis.Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			id, key, found := strings.Cut(msg.Payload, invalidateSeparator)
+			if !found || id == lc.instanceID {
+				continue
+			}
+			lc.lru.Remove(key)
+		}
+	}
+}
+
+// publish แจ้ง instance อื่นให้ลบ key (ใน pipeline เดียวกับคำสั่งเขียน)
+func (lc *localCache) publish(ctx context.Context, pipe redis.Pipeliner, keys ...string) {
+	for _, key := range keys {
+		pipe.Publish(ctx, lc.channel, lc.instanceID+invalidateSeparator+key)
+	}
+}
+
+// invalidate ลบ key ใน local และแจ้ง instance อื่น
+func (lc *localCache) invalidate(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		lc.lru.Remove(key)
+	}
+	lc.notify(ctx, keys...)
+}
+
+// notify แจ้ง instance อื่นให้ลบ key
+func (lc *localCache) notify(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	_, err := lc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		lc.publish(ctx, pipe, keys...)
+		return nil
+	})
+	if err != nil {
+		logger.Log.Warnf("publish cache invalidate error: %s", err)
+	}
+}
+
+// layered cache 2 ชั้น local lru -> redis
+type layered struct {
+	local  *localCache
+	remote *connection
+}
+
+func (l *layered) Ping(ctx context.Context) error {
+	return l.remote.Ping(ctx)
+}
+
+// PoolStats pool stats redis connection
+func (l *layered) PoolStats() *redis.PoolStats {
+	return l.remote.PoolStats()
+}
+
+// GetBytes get raw bytes จาก local ก่อน ถ้าไม่เจอดึงจาก redis
+func (l *layered) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	if data, ok := l.local.lru.Get(key); ok {
+		metrics.CacheLocalHit()
+		return data, nil
+	}
+
+	data, err := l.remote.GetBytes(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	l.local.lru.Add(key, data)
+
+	return data, nil
+}
+
+// MGetBytes get จาก local ก่อน key ที่เหลือดึงจาก redis ครั้งเดียว
+func (l *layered) MGetBytes(ctx context.Context, keys ...string) ([][]byte, error) {
+	res := make([][]byte, len(keys))
+	var missKeys []string
+	var missIndex []int
+	for i, key := range keys {
+		if data, ok := l.local.lru.Get(key); ok {
+			metrics.CacheLocalHit()
+			res[i] = data
+			continue
+		}
+		missKeys = append(missKeys, key)
+		missIndex = append(missIndex, i)
+	}
+	if len(missKeys) == 0 {
+		return res, nil
+	}
+
+	values, err := l.remote.MGetBytes(ctx, missKeys...)
+	if err != nil {
+		return nil, err
+	}
+	for i, data := range values {
+		if data == nil {
+			continue
+		}
+		res[missIndex[i]] = data
+		l.local.lru.Add(missKeys[i], data)
+	}
+
+	return res, nil
+}
+
+// SetBytes set raw bytes ทั้ง 2 ชั้น และแจ้ง instance อื่นให้ลบค่าเก่า
+func (l *layered) SetBytes(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return l.MSetBytes(ctx, Item{Key: key, Value: value, TTL: ttl})
+}
+
+// MSetBytes set และ publish invalidate ใน pipeline เดียว
+func (l *layered) MSetBytes(ctx context.Context, items ...Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var ttls []tagTTL
+	_, err := l.remote.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		ttls = l.remote.setItems(ctx, pipe, items)
+		for _, item := range items {
+			l.local.publish(ctx, pipe, item.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		l.local.lru.Add(item.Key, item.Value)
+	}
+
+	return l.remote.extendTags(ctx, ttls)
+}
+
+func (l *layered) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ok, err := l.remote.SetNX(ctx, key, value, ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+	l.local.invalidate(ctx, key)
+
+	return true, nil
+}
+
+func (l *layered) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	return l.remote.CompareAndDelete(ctx, key, value)
+}
+
+func (l *layered) CompareAndExpire(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return l.remote.CompareAndExpire(ctx, key, value, ttl)
+}
+
+func (l *layered) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := l.remote.Incr(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	l.local.invalidate(ctx, key)
+
+	return n, nil
+}
+
+func (l *layered) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return l.remote.Expire(ctx, key, ttl)
+}
+
+func (l *layered) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return l.remote.TTL(ctx, key)
+}
+
+// Delete ลบ key ทั้ง 2 ชั้น และแจ้ง instance อื่น
+func (l *layered) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	for _, key := range keys {
+		l.local.lru.Remove(key)
+	}
+
+	_, err := l.remote.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		l.remote.deleteKeys(ctx, pipe, keys)
+		l.local.publish(ctx, pipe, keys...)
+		return nil
+	})
+
+	return err
+}
+
+// SetWithTags set พร้อม tag ทั้ง 2 ชั้น
+func (l *layered) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	return l.MSetBytes(ctx, Item{Key: key, Value: value, TTL: ttl, Tags: tags})
+}
+
+// InvalidateTag ลบทุก key ของ tag ทั้ง 2 ชั้น และแจ้ง instance อื่น
+func (l *layered) InvalidateTag(ctx context.Context, tags ...string) error {
+	keys, err := l.remote.invalidateTags(ctx, tags...)
+	l.local.invalidate(ctx, keys...)
+
+	return err
+}
+
+func (l *layered) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return l.remote.Keys(ctx, pattern)
+}
+
+// Close close connection
+func (l *layered) Close() error {
+	return l.remote.Close()
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedis redis store บน miniredis
+func newTestRedis(t *testing.T, mr *miniredis.Miniredis, prefix string) Service {
+	t.Helper()
+
+	store, err := newRedis(&Configuration{Addrs: []string{mr.Addr()}, KeyPrefix: prefix})
+	if err != nil {
+		t.Fatalf("newRedis: %s", err)
+	}
+	s := New(store)
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+// newTestLocal local cache หน้า redis store บน miniredis
+func newTestLocal(t *testing.T, mr *miniredis.Miniredis, prefix string) Service {
+	t.Helper()
+
+	s, stop, err := NewLocal(newTestRedis(t, mr, prefix), &LocalConfiguration{})
+	if err != nil {
+		t.Fatalf("NewLocal: %s", err)
+	}
+	t.Cleanup(func() { _ = stop(context.Background()) })
+
+	return s
+}
+
+// waitSubscribers รอจน channel มีผู้ subscribe ครบ
+func waitSubscribers(t *testing.T, mr *miniredis.Miniredis, channel string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.PubSubNumSub(channel)[channel] < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("channel %s: expected %d subscribers, got %d", channel, n, mr.PubSubNumSub(channel)[channel])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitValue รอจน key มีค่าตามที่ต้องการ
+func waitValue(t *testing.T, s Store, key string, want []byte) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, err := s.GetBytes(context.Background(), key)
+		if err == nil && bytes.Equal(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetBytes(%q) = %q, %v; want %q", key, got, err, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLocalInvalidateWithPrefix(t *testing.T) {
+	for _, prefix := range []string{"", "reraw:"} {
+		t.Run("prefix="+prefix, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			a := newTestLocal(t, mr, prefix).Store()
+			b := newTestLocal(t, mr, prefix).Store()
+			waitSubscribers(t, mr, prefix+defaultLocalChannel, 2)
+
+			ctx := context.Background()
+			key := "user:alice"
+
+			// a อ่านค่าเก่าเข้า local lru
+			err := a.SetBytes(ctx, key, []byte("v1"), time.Minute)
+			if err != nil {
+				t.Fatalf("SetBytes: %s", err)
+			}
+			waitValue(t, a, key, []byte("v1"))
+
+			// b เขียนค่าใหม่ a ต้องเห็นค่าใหม่ ไม่ใช่ค่าใน lru
+			err = b.SetBytes(ctx, key, []byte("v2"), time.Minute)
+			if err != nil {
+				t.Fatalf("SetBytes: %s", err)
+			}
+			waitValue(t, a, key, []byte("v2"))
+
+			// b ลบ key a ต้องไม่เจอ
+			err = b.Delete(ctx, key)
+			if err != nil {
+				t.Fatalf("Delete: %s", err)
+			}
+			deadline := time.Now().Add(2 * time.Second)
+			for {
+				_, err := a.GetBytes(ctx, key)
+				if err == ErrMiss {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("GetBytes after delete: expected ErrMiss, got %v", err)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package lock
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+// Election เลือก leader หนึ่ง instance จากทุก instance ที่ใช้ชื่อเดียวกัน
+// leader ถือ lease ไว้และต่ออายุเป็นระยะ ถ้า leader หายไป instance อื่นจะขึ้นแทนเมื่อ lease หมดอายุ
+type Election struct {
+	name   string
+	mutex  *Mutex
+	leader atomic.Bool
+	renew  time.Time // เวลาที่ต่ออายุ lease สำเร็จล่าสุด
+}
+
+// NewElection new leader election
+func NewElection(store cache.Store, name string, ttl time.Duration) *Election {
+	return &Election{
+		name:  name,
+		mutex: New(store, "leader:"+name, ttl),
+	}
+}
+
+// IsLeader instance นี้เป็น leader อยู่หรือไม่
+func (e *Election) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run ลงสมัคร leader และต่ออายุ lease ทุก 1/3 ของ ttl จนกว่า context จะถูกยกเลิก
+// เมื่อหยุดจะสละตำแหน่ง leader ทันที
+func (e *Election) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.mutex.TTL() / 3)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			if e.leader.Swap(false) {
+				_ = e.mutex.Unlock(context.WithoutCancel(ctx))
+				logger.Log.Infof("resigned leader of %s", e.name)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign ต่ออายุ lease ถ้าเป็น leader ไม่เช่นนั้นพยายามขึ้นเป็น leader
+func (e *Election) campaign(ctx context.Context) {
+	if e.leader.Load() {
+		err := e.mutex.Refresh(ctx)
+		if err == nil {
+			e.renew = time.Now()
+			return
+		}
+
+		// error ชั่วคราว ยังเป็น leader ได้จนกว่า lease เดิมจะหมดอายุ
+		if err != ErrLost && time.Since(e.renew) < e.mutex.TTL() {
+			logger.Log.Warnf("renew leader of %s error: %s", e.name, err)
+			return
+		}
+
+		e.leader.Store(false)
+		logger.Log.Warnf("lost leader of %s: %s", e.name, err)
+	}
+
+	ok, err := e.mutex.TryLock(ctx)
+	if err != nil {
+		logger.Log.Warnf("campaign leader of %s error: %s", e.name, err)
+		return
+	}
+	if ok {
+		e.renew = time.Now()
+		e.leader.Store(true)
+		logger.Log.Infof("elected leader of %s", e.name)
+	}
+}
@@ -0,0 +1,187 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+)
+
+var (
+	keyPrefix = "lock:"
+
+	defaultTTL        = 30 * time.Second
+	defaultRetryDelay = 100 * time.Millisecond
+)
+
+var (
+	// ErrNotAcquired lock ถูกถือโดย instance อื่น
+	ErrNotAcquired = errors.New("lock: not acquired")
+
+	// ErrLost lease หมดอายุหรือถูก instance อื่นเอาไป
+	ErrLost = errors.New("lock: lease lost")
+)
+
+// Mutex lease-based mutex บน cache
+// ถือ lock ได้นานเท่า ttl ต้อง Refresh ก่อนหมดอายุ
+type Mutex struct {
+	store cache.Store
+	key   string
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	token []byte
+}
+
+// New new mutex
+// ttl <= 0 ใช้ค่า default 30 วินาที
+func New(store cache.Store, name string, ttl time.Duration) *Mutex {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	return &Mutex{
+		store: store,
+		key:   keyPrefix + name,
+		ttl:   ttl,
+	}
+}
+
+// TTL อายุของ lease
+func (m *Mutex) TTL() time.Duration {
+	return m.ttl
+}
+
+// TryLock พยายามถือ lock ครั้งเดียว
+func (m *Mutex) TryLock(ctx context.Context) (bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := m.store.SetNX(ctx, m.key, token, m.ttl)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	m.mu.Lock()
+	m.token = token
+	m.mu.Unlock()
+
+	return true, nil
+}
+
+// Lock รอจนกว่าจะถือ lock ได้หรือ context ถูกยกเลิก
+func (m *Mutex) Lock(ctx context.Context) error {
+	ticker := time.NewTicker(defaultRetryDelay)
+	defer ticker.Stop()
+
+	for {
+		ok, err := m.TryLock(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh ต่ออายุ lease เฉพาะเมื่อยังเป็นเจ้าของ lock
+func (m *Mutex) Refresh(ctx context.Context) error {
+	token := m.currentToken()
+	if token == nil {
+		return ErrNotAcquired
+	}
+
+	ok, err := m.store.CompareAndExpire(ctx, m.key, token, m.ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		m.clearToken(token)
+		return ErrLost
+	}
+
+	return nil
+}
+
+// Unlock ปล่อย lock เฉพาะเมื่อยังเป็นเจ้าของ lock
+func (m *Mutex) Unlock(ctx context.Context) error {
+	token := m.currentToken()
+	if token == nil {
+		return ErrNotAcquired
+	}
+	m.clearToken(token)
+
+	ok, err := m.store.CompareAndDelete(ctx, m.key, token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLost
+	}
+
+	return nil
+}
+
+// KeepAlive ต่ออายุ lease ทุก 1/3 ของ ttl จนกว่า context จะถูกยกเลิก
+// context ที่คืนจะถูกยกเลิกเมื่อเสีย lease ไป
+func (m *Mutex) KeepAlive(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+
+		ticker := time.NewTicker(m.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Refresh(ctx); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, cancel
+}
+
+func (m *Mutex) currentToken() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.token
+}
+
+// clearToken ล้าง token ถ้ายังเป็นค่าเดิม
+func (m *Mutex) clearToken(token []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if string(m.token) == string(token) {
+		m.token = nil
+	}
+}
+
+// newToken สุ่ม token ของ lease
+func newToken() ([]byte, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return []byte(hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,183 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+func init() {
+	logger.InitLogger()
+}
+
+// newTestStore memory store สำหรับ test
+func newTestStore(t *testing.T) cache.Store {
+	t.Helper()
+
+	s, err := cache.Open(&cache.Configuration{Driver: cache.DriverMemory})
+	if err != nil {
+		t.Fatalf("open cache: %s", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s.Store()
+}
+
+func TestMutex(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		run  func(t *testing.T, a, b *Mutex)
+	}{
+		{
+			name: "only one holder",
+			run: func(t *testing.T, a, b *Mutex) {
+				if ok, err := a.TryLock(ctx); !ok || err != nil {
+					t.Fatalf("a.TryLock = %v, %v; want true", ok, err)
+				}
+				if ok, err := b.TryLock(ctx); ok || err != nil {
+					t.Fatalf("b.TryLock = %v, %v; want false", ok, err)
+				}
+			},
+		},
+		{
+			name: "lock after unlock",
+			run: func(t *testing.T, a, b *Mutex) {
+				_, _ = a.TryLock(ctx)
+				if err := a.Unlock(ctx); err != nil {
+					t.Fatalf("a.Unlock: %s", err)
+				}
+				if ok, err := b.TryLock(ctx); !ok || err != nil {
+					t.Fatalf("b.TryLock = %v, %v; want true", ok, err)
+				}
+			},
+		},
+		{
+			name: "unlock without holding",
+			run: func(t *testing.T, a, b *Mutex) {
+				_, _ = a.TryLock(ctx)
+				if err := b.Unlock(ctx); !errors.Is(err, ErrNotAcquired) {
+					t.Fatalf("b.Unlock = %v, want ErrNotAcquired", err)
+				}
+				if err := b.Refresh(ctx); !errors.Is(err, ErrNotAcquired) {
+					t.Fatalf("b.Refresh = %v, want ErrNotAcquired", err)
+				}
+			},
+		},
+		{
+			name: "lease expired and taken",
+			run: func(t *testing.T, a, b *Mutex) {
+				_, _ = a.TryLock(ctx)
+				time.Sleep(2 * a.TTL())
+				if ok, err := b.TryLock(ctx); !ok || err != nil {
+					t.Fatalf("b.TryLock = %v, %v; want true", ok, err)
+				}
+				if err := a.Refresh(ctx); !errors.Is(err, ErrLost) {
+					t.Fatalf("a.Refresh = %v, want ErrLost", err)
+				}
+				// a ต้องไม่ลบ lock ของ b
+				if err := a.Unlock(ctx); !errors.Is(err, ErrNotAcquired) {
+					t.Fatalf("a.Unlock = %v, want ErrNotAcquired", err)
+				}
+				if err := b.Refresh(ctx); err != nil {
+					t.Fatalf("b.Refresh: %s", err)
+				}
+			},
+		},
+		{
+			name: "refresh keeps lease",
+			run: func(t *testing.T, a, b *Mutex) {
+				_, _ = a.TryLock(ctx)
+				for i := 0; i < 4; i++ {
+					time.Sleep(a.TTL() / 2)
+					if err := a.Refresh(ctx); err != nil {
+						t.Fatalf("a.Refresh: %s", err)
+					}
+				}
+				if ok, _ := b.TryLock(ctx); ok {
+					t.Fatal("b.TryLock = true while a refreshes lease")
+				}
+			},
+		},
+		{
+			name: "lock waits for release",
+			run: func(t *testing.T, a, b *Mutex) {
+				_, _ = a.TryLock(ctx)
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					_ = a.Unlock(ctx)
+				}()
+
+				ctx, cancel := context.WithTimeout(ctx, time.Second)
+				defer cancel()
+				if err := b.Lock(ctx); err != nil {
+					t.Fatalf("b.Lock: %s", err)
+				}
+			},
+		},
+		{
+			name: "lock canceled",
+			run: func(t *testing.T, a, b *Mutex) {
+				_, _ = a.TryLock(ctx)
+				ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				defer cancel()
+				if err := b.Lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("b.Lock = %v, want DeadlineExceeded", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			tt.run(t, New(store, "test", 100*time.Millisecond), New(store, "test", 100*time.Millisecond))
+		})
+	}
+}
+
+func TestElection(t *testing.T) {
+	store := newTestStore(t)
+	ttl := 150 * time.Millisecond
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	defer cancelA()
+	a := NewElection(store, "test", ttl)
+	go a.Run(ctxA)
+
+	waitLeader(t, a)
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	b := NewElection(store, "test", ttl)
+	go b.Run(ctxB)
+
+	time.Sleep(ttl)
+	if b.IsLeader() {
+		t.Fatal("b is leader while a holds the lease")
+	}
+
+	// a สละตำแหน่ง b ต้องขึ้นแทน
+	cancelA()
+	waitLeader(t, b)
+	if a.IsLeader() {
+		t.Fatal("a is still leader after stop")
+	}
+}
+
+// waitLeader รอจน election เป็น leader
+func waitLeader(t *testing.T, e *Election) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !e.IsLeader() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: not elected", e.name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
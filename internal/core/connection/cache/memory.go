@@ -0,0 +1,307 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/saveblush/reraw-api/internal/core/metrics"
+)
+
+var defaultMemorySize = 10000
+
+// memoryEntry ค่าใน memory cache พร้อมเวลาหมดอายุ
+type memoryEntry struct {
+	value    []byte
+	expireAt time.Time
+}
+
+// expired check หมดอายุ (expireAt เป็น zero = ไม่หมดอายุ)
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && now.After(e.expireAt)
+}
+
+// memory cache ใน process สำหรับระบบที่ไม่มี redis
+// จำกัดจำนวน key ด้วย lru ข้อมูลไม่แชร์ข้าม instance
+type memory struct {
+	mu      sync.Mutex
+	lru     *simplelru.LRU[string, *memoryEntry]
+	tags    map[string]map[string]struct{} // tag -> keys
+	keyTags map[string][]string            // key -> tags
+}
+
+func newMemory(cf *Configuration) (Store, error) {
+	size := cf.Size
+	if size <= 0 {
+		size = defaultMemorySize
+	}
+
+	m := &memory{
+		tags:    make(map[string]map[string]struct{}),
+		keyTags: make(map[string][]string),
+	}
+	lru, err := simplelru.NewLRU[string, *memoryEntry](size, m.untag)
+	if err != nil {
+		return nil, err
+	}
+	m.lru = lru
+
+	return m, nil
+}
+
+// untag เอา key ออกจาก tag เมื่อ key ถูกลบหรือถูก evict
+func (m *memory) untag(key string, _ *memoryEntry) {
+	for _, tag := range m.keyTags[key] {
+		delete(m.tags[tag], key)
+		if len(m.tags[tag]) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+	delete(m.keyTags, key)
+}
+
+// expireAt เวลาหมดอายุจาก ttl (<= 0 ไม่หมดอายุ)
+func expireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(ttl)
+}
+
+// get get entry ที่ยังไม่หมดอายุ ต้องถือ lock ก่อนเรียก
+func (m *memory) get(key string) (*memoryEntry, bool) {
+	e, ok := m.lru.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if e.expired(time.Now()) {
+		m.lru.Remove(key)
+		return nil, false
+	}
+
+	return e, true
+}
+
+func (m *memory) Ping(context.Context) error {
+	return nil
+}
+
+func (m *memory) GetBytes(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.get(key)
+	if !ok {
+		metrics.CacheMiss()
+		return nil, ErrMiss
+	}
+	metrics.CacheHit()
+
+	return e.value, nil
+}
+
+func (m *memory) MGetBytes(_ context.Context, keys ...string) ([][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([][]byte, len(keys))
+	for i, key := range keys {
+		e, ok := m.get(key)
+		if !ok {
+			metrics.CacheMiss()
+			continue
+		}
+		metrics.CacheHit()
+		res[i] = e.value
+	}
+
+	return res, nil
+}
+
+func (m *memory) SetBytes(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	m.lru.Add(key, &memoryEntry{value: value, expireAt: expireAt(ttl)})
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *memory) MSetBytes(_ context.Context, items ...Item) error {
+	m.mu.Lock()
+	for _, item := range items {
+		m.set(item)
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+// set set item พร้อม tag ต้องถือ lock ก่อนเรียก
+func (m *memory) set(item Item) {
+	m.lru.Add(item.Key, &memoryEntry{value: item.Value, expireAt: expireAt(item.TTL)})
+	for _, tag := range item.Tags {
+		keys, ok := m.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			m.tags[tag] = keys
+		}
+		if _, ok := keys[item.Key]; ok {
+			continue
+		}
+		keys[item.Key] = struct{}{}
+		m.keyTags[item.Key] = append(m.keyTags[item.Key], tag)
+	}
+}
+
+func (m *memory) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.get(key); ok {
+		return false, nil
+	}
+	m.lru.Add(key, &memoryEntry{value: value, expireAt: expireAt(ttl)})
+
+	return true, nil
+}
+
+func (m *memory) CompareAndDelete(_ context.Context, key string, value []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.get(key)
+	if !ok || !bytes.Equal(e.value, value) {
+		return false, nil
+	}
+	m.lru.Remove(key)
+
+	return true, nil
+}
+
+func (m *memory) CompareAndExpire(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.get(key)
+	if !ok || !bytes.Equal(e.value, value) {
+		return false, nil
+	}
+	e.expireAt = expireAt(ttl)
+
+	return true, nil
+}
+
+// Incr เพิ่มค่าตัวเลข 1 ถ้าไม่มี key จะเริ่มที่ 0 และไม่หมดอายุ
+func (m *memory) Incr(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	e, ok := m.get(key)
+	if ok {
+		v, err := strconv.ParseInt(string(e.value), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		n = v
+	} else {
+		e = &memoryEntry{}
+		m.lru.Add(key, e)
+	}
+
+	n++
+	e.value = []byte(strconv.FormatInt(n, 10))
+
+	return n, nil
+}
+
+func (m *memory) Expire(_ context.Context, key string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.get(key)
+	if !ok {
+		return ErrMiss
+	}
+	e.expireAt = expireAt(ttl)
+
+	return nil
+}
+
+func (m *memory) TTL(_ context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.get(key)
+	if !ok {
+		return 0, ErrMiss
+	}
+	if e.expireAt.IsZero() {
+		return 0, nil
+	}
+
+	return time.Until(e.expireAt), nil
+}
+
+func (m *memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	for _, key := range keys {
+		m.lru.Remove(key)
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Keys get keys ตาม glob pattern
+func (m *memory) Keys(_ context.Context, pattern string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var keys []string
+	for _, key := range m.lru.Keys() {
+		e, ok := m.lru.Peek(key)
+		if !ok || e.expired(now) {
+			continue
+		}
+		if matched, _ := path.Match(pattern, key); matched {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (m *memory) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	return m.MSetBytes(ctx, Item{Key: key, Value: value, TTL: ttl, Tags: tags})
+}
+
+func (m *memory) InvalidateTag(_ context.Context, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			m.lru.Remove(key)
+		}
+		delete(m.tags, tag)
+	}
+
+	return nil
+}
+
+// Close clear ข้อมูลทั้งหมด
+func (m *memory) Close() error {
+	m.mu.Lock()
+	m.lru.Purge()
+	m.mu.Unlock()
+
+	return nil
+}
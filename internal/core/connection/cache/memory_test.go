@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestMemory memory store สำหรับ test
+func newTestMemory(t *testing.T, size int) Store {
+	t.Helper()
+
+	s, err := newMemory(&Configuration{Size: size})
+	if err != nil {
+		t.Fatalf("newMemory: %s", err)
+	}
+
+	return s
+}
+
+type testValue struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+}
+
+func TestMemoryGetSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     map[string]testValue
+		ttl     time.Duration
+		wait    time.Duration
+		get     string
+		want    testValue
+		wantErr error
+	}{
+		{
+			name: "hit",
+			set:  map[string]testValue{"a": {Name: "alice", N: 1}},
+			get:  "a",
+			want: testValue{Name: "alice", N: 1},
+		},
+		{
+			name:    "miss",
+			set:     map[string]testValue{"a": {Name: "alice"}},
+			get:     "b",
+			wantErr: ErrMiss,
+		},
+		{
+			name: "not expired",
+			set:  map[string]testValue{"a": {Name: "alice"}},
+			ttl:  time.Minute,
+			get:  "a",
+			want: testValue{Name: "alice"},
+		},
+		{
+			name:    "expired",
+			set:     map[string]testValue{"a": {Name: "alice"}},
+			ttl:     time.Millisecond,
+			wait:    5 * time.Millisecond,
+			get:     "a",
+			wantErr: ErrMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestMemory(t, 0)
+			ctx := context.Background()
+			for key, value := range tt.set {
+				err := Set(ctx, s, key, value, tt.ttl)
+				if err != nil {
+					t.Fatalf("Set: %s", err)
+				}
+			}
+			time.Sleep(tt.wait)
+
+			got, err := Get[testValue](ctx, s, tt.get)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get(%q) error = %v, want %v", tt.get, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Get(%q) = %+v, want %+v", tt.get, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryMGet(t *testing.T) {
+	tests := []struct {
+		name string
+		set  map[string]testValue
+		keys []string
+		want map[string]testValue
+	}{
+		{
+			name: "no keys",
+			set:  map[string]testValue{"a": {Name: "alice"}},
+			keys: nil,
+			want: map[string]testValue{},
+		},
+		{
+			name: "all hit",
+			set:  map[string]testValue{"a": {Name: "alice"}, "b": {Name: "bob"}},
+			keys: []string{"a", "b"},
+			want: map[string]testValue{"a": {Name: "alice"}, "b": {Name: "bob"}},
+		},
+		{
+			name: "partial hit",
+			set:  map[string]testValue{"a": {Name: "alice"}},
+			keys: []string{"a", "b"},
+			want: map[string]testValue{"a": {Name: "alice"}},
+		},
+		{
+			name: "all miss",
+			set:  nil,
+			keys: []string{"a", "b"},
+			want: map[string]testValue{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestMemory(t, 0)
+			ctx := context.Background()
+			err := MSet(ctx, s, tt.set, time.Minute)
+			if err != nil {
+				t.Fatalf("MSet: %s", err)
+			}
+
+			got, err := MGet[testValue](ctx, s, tt.keys...)
+			if err != nil {
+				t.Fatalf("MGet: %s", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("MGet(%v) = %+v, want %+v", tt.keys, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      []Item
+		invalidate []string
+		wantHit    []string
+		wantMiss   []string
+	}{
+		{
+			name: "invalidate one tag",
+			items: []Item{
+				{Key: "a", Tags: []string{"users"}},
+				{Key: "b", Tags: []string{"users"}},
+				{Key: "c", Tags: []string{"relays"}},
+			},
+			invalidate: []string{"users"},
+			wantHit:    []string{"c"},
+			wantMiss:   []string{"a", "b"},
+		},
+		{
+			name: "key with many tags",
+			items: []Item{
+				{Key: "a", Tags: []string{"users", "relays"}},
+				{Key: "b", Tags: []string{"users"}},
+			},
+			invalidate: []string{"relays"},
+			wantHit:    []string{"b"},
+			wantMiss:   []string{"a"},
+		},
+		{
+			name: "many tags at once",
+			items: []Item{
+				{Key: "a", Tags: []string{"users"}},
+				{Key: "b", Tags: []string{"relays"}},
+				{Key: "c"},
+			},
+			invalidate: []string{"users", "relays"},
+			wantHit:    []string{"c"},
+			wantMiss:   []string{"a", "b"},
+		},
+		{
+			name: "unknown tag",
+			items: []Item{
+				{Key: "a", Tags: []string{"users"}},
+			},
+			invalidate: []string{"missing"},
+			wantHit:    []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestMemory(t, 0)
+			ctx := context.Background()
+			for _, item := range tt.items {
+				err := s.SetWithTags(ctx, item.Key, []byte(item.Key), time.Minute, item.Tags...)
+				if err != nil {
+					t.Fatalf("SetWithTags: %s", err)
+				}
+			}
+
+			err := s.InvalidateTag(ctx, tt.invalidate...)
+			if err != nil {
+				t.Fatalf("InvalidateTag: %s", err)
+			}
+
+			for _, key := range tt.wantHit {
+				if _, err := s.GetBytes(ctx, key); err != nil {
+					t.Fatalf("GetBytes(%q) error = %v, want hit", key, err)
+				}
+			}
+			for _, key := range tt.wantMiss {
+				if _, err := s.GetBytes(ctx, key); !errors.Is(err, ErrMiss) {
+					t.Fatalf("GetBytes(%q) error = %v, want ErrMiss", key, err)
+				}
+			}
+		})
+	}
+}
+
+// key ที่ถูก evict ต้องถูกเอาออกจาก tag ด้วย
+func TestMemoryEvictUntag(t *testing.T) {
+	s := newTestMemory(t, 1)
+	ctx := context.Background()
+
+	err := s.SetWithTags(ctx, "a", []byte("a"), 0, "users")
+	if err != nil {
+		t.Fatalf("SetWithTags: %s", err)
+	}
+	err = s.SetBytes(ctx, "b", []byte("b"), 0)
+	if err != nil {
+		t.Fatalf("SetBytes: %s", err)
+	}
+
+	m := s.(*memory)
+	if _, ok := m.tags["users"]; ok {
+		t.Fatalf("tag users still has keys %v after evict", m.tags["users"])
+	}
+	if _, ok := m.keyTags["a"]; ok {
+		t.Fatalf("key a still has tags %v after evict", m.keyTags["a"])
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// noop cache ที่ไม่เก็บอะไรเลย ทุกการ get จะ miss
+type noop struct{}
+
+func newNoop(*Configuration) (Store, error) {
+	return noop{}, nil
+}
+
+// IsNoop store เป็น noop หรือไม่
+// lock บน noop ได้ทุกครั้ง จึงใช้กันงานซ้ำข้าม instance ไม่ได้
+func IsNoop(s Store) bool {
+	_, ok := s.(noop)
+	return ok
+}
+
+func (noop) Ping(context.Context) error {
+	return nil
+}
+
+func (noop) GetBytes(context.Context, string) ([]byte, error) {
+	return nil, ErrMiss
+}
+
+func (noop) MGetBytes(_ context.Context, keys ...string) ([][]byte, error) {
+	return make([][]byte, len(keys)), nil
+}
+
+func (noop) SetBytes(context.Context, string, []byte, time.Duration) error {
+	return nil
+}
+
+func (noop) MSetBytes(context.Context, ...Item) error {
+	return nil
+}
+
+// SetNX ไม่มี key อยู่เสมอ จึง set สำเร็จทุกครั้ง
+func (noop) SetNX(context.Context, string, []byte, time.Duration) (bool, error) {
+	return true, nil
+}
+
+// CompareAndDelete ไม่มีค่าให้เทียบ ถือว่าสำเร็จเสมอ
+func (noop) CompareAndDelete(context.Context, string, []byte) (bool, error) {
+	return true, nil
+}
+
+// CompareAndExpire ไม่มีค่าให้เทียบ ถือว่าสำเร็จเสมอ
+func (noop) CompareAndExpire(context.Context, string, []byte, time.Duration) (bool, error) {
+	return true, nil
+}
+
+// Incr ไม่เก็บค่า ทุกครั้งเหมือนเพิ่งสร้าง key
+func (noop) Incr(context.Context, string) (int64, error) {
+	return 1, nil
+}
+
+func (noop) Expire(context.Context, string, time.Duration) error {
+	return ErrMiss
+}
+
+func (noop) TTL(context.Context, string) (time.Duration, error) {
+	return 0, ErrMiss
+}
+
+func (noop) Delete(context.Context, ...string) error {
+	return nil
+}
+
+func (noop) Keys(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+func (noop) SetWithTags(context.Context, string, []byte, time.Duration, ...string) error {
+	return nil
+}
+
+func (noop) InvalidateTag(context.Context, ...string) error {
+	return nil
+}
+
+func (noop) Close() error {
+	return nil
+}
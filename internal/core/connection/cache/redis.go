@@ -2,118 +2,466 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/goccy/go-json"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/saveblush/reraw-api/internal/core/metrics"
+	"github.com/saveblush/reraw-api/internal/core/utils/retry"
+)
+
+var (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
 )
 
-var client *redis.Client
+// Configuration config cache connection
+type Configuration struct {
+	Driver string // redis, memory, noop
+	Size   int    // จำนวน key สูงสุดของ memory driver
+	Retry  retry.Policy
+
+	// redis
+	Mode             string   // standalone, sentinel, cluster
+	Host             string   // ใช้เมื่อไม่กำหนด Addrs
+	Port             int      // ใช้เมื่อไม่กำหนด Addrs
+	Addrs            []string // address ของ sentinel หรือ cluster node
+	MasterName       string   // ชื่อ master ของ sentinel
+	Username         string   // acl username
+	Password         string
+	SentinelUsername string
+	SentinelPassword string
+	DB               int
+	PoolSize         int
+	MinIdleConns     int
+	DialTimeout      time.Duration
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+	KeyPrefix        string // prefix ทุก key เช่น "reraw:"
+	TLS              TLSConfiguration
+}
+
+// TLSConfiguration config tls ของ redis
+type TLSConfiguration struct {
+	Enable             bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+var (
+	// compareAndDeleteScript ลบ key เมื่อค่าตรงกัน
+	compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
 
+	// compareAndExpireScript ต่ออายุ key เมื่อค่าตรงกัน
+	compareAndExpireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+// connection redis store
 type connection struct {
-	client *redis.Client
-	ctx    context.Context
+	client redis.UniversalClient
+	prefix string
 }
 
-// Configuration config redis connection
-type Configuration struct {
-	Host     string
-	Port     int
-	Username string
-	Password string
-	DB       int
-}
-
-// Init init a new redis connection
-func Init(cf *Configuration) error {
-	addr := fmt.Sprintf("%s:%d", cf.Host, cf.Port)
-	conn := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Username: cf.Username,
-		Password: cf.Password,
-		DB:       cf.DB,
-	})
+// newRedis open redis connection pool without ping
+// connection จริงจะถูกสร้างเมื่อมีการใช้งาน
+func newRedis(cf *Configuration) (Store, error) {
+	addrs := cf.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{fmt.Sprintf("%s:%d", cf.Host, cf.Port)}
+	}
 
-	if err := conn.Ping(context.TODO()).Err(); err != nil {
-		return err
+	tlsConfig, err := newTLSConfig(&cf.TLS)
+	if err != nil {
+		return nil, err
 	}
 
-	client = conn
+	opts := &redis.UniversalOptions{
+		Addrs:            addrs,
+		MasterName:       cf.MasterName,
+		Username:         cf.Username,
+		Password:         cf.Password,
+		SentinelUsername: cf.SentinelUsername,
+		SentinelPassword: cf.SentinelPassword,
+		DB:               cf.DB,
+		PoolSize:         cf.PoolSize,
+		MinIdleConns:     cf.MinIdleConns,
+		DialTimeout:      cf.DialTimeout,
+		ReadTimeout:      cf.ReadTimeout,
+		WriteTimeout:     cf.WriteTimeout,
+		TLSConfig:        tlsConfig,
+	}
 
-	return nil
-}
+	var client redis.UniversalClient
+	switch cf.Mode {
+	case "", ModeStandalone:
+		client = redis.NewClient(opts.Simple())
+	case ModeSentinel:
+		if cf.MasterName == "" {
+			return nil, errors.New("redis sentinel requires master name")
+		}
+		client = redis.NewFailoverClient(opts.Failover())
+	case ModeCluster:
+		client = redis.NewClusterClient(opts.Cluster())
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", cf.Mode)
+	}
+	client.AddHook(tracingHook{})
 
-// New new client connection
-func New() *connection {
 	return &connection{
 		client: client,
-		ctx:    context.Background(),
+		prefix: cf.KeyPrefix,
+	}, nil
+}
+
+// newTLSConfig new tls config จากไฟล์ certificate
+func newTLSConfig(cf *TLSConfiguration) (*tls.Config, error) {
+	if !cf.Enable {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cf.ServerName,
+		InsecureSkipVerify: cf.InsecureSkipVerify,
 	}
+
+	if cf.CAFile != "" {
+		ca, err := os.ReadFile(cf.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid redis ca file %s", cf.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cf.CertFile != "" || cf.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cf.CertFile, cf.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
 }
 
-// Service service interface
-type Service interface {
-	Set(key string, value interface{}, expiredTime time.Duration) error
-	Get(key string, value interface{}) error
-	GetKeys(pattern string) ([]string, error)
-	Delete(key string) error
-	Close() error
+// key เติม prefix ให้ key
+func (c *connection) key(key string) string {
+	return c.prefix + key
 }
 
-func (c *connection) Set(key string, value interface{}, expiredTime time.Duration) error {
-	data, errMar := json.Marshal(&value)
-	if errMar != nil {
-		return errMar
+// keys เติม prefix ให้หลาย key
+func (c *connection) keys(keys []string) []string {
+	res := make([]string, len(keys))
+	for i, key := range keys {
+		res[i] = c.prefix + key
 	}
 
-	err := c.client.Set(c.ctx, key, data, expiredTime).Err()
-	if err != nil {
-		return err
-	}
+	return res
+}
 
-	return nil
+// Ping ping redis connection
+func (c *connection) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
 }
 
-func (c *connection) Get(key string, value interface{}) error {
-	val, err := c.client.Get(c.ctx, key).Result()
+// PoolStats pool stats redis connection
+func (c *connection) PoolStats() *redis.PoolStats {
+	return c.client.PoolStats()
+}
+
+func (c *connection) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	val, err := c.client.Get(ctx, c.key(key)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return errors.New("key does not exists")
+			metrics.CacheMiss()
+			return nil, ErrMiss
 		}
 
+		metrics.CacheError()
+		return nil, err
+	}
+	metrics.CacheHit()
+
+	return val, nil
+}
+
+// MGetBytes get หลาย key ผ่าน pipeline
+// ไม่ใช้ MGET เพราะ cluster ไม่รองรับ key ต่าง slot
+func (c *connection) MGetBytes(ctx context.Context, keys ...string) ([][]byte, error) {
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, c.key(key))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		metrics.CacheError()
+		return nil, err
+	}
+
+	res := make([][]byte, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Bytes()
+		if err != nil {
+			metrics.CacheMiss()
+			continue
+		}
+		metrics.CacheHit()
+		res[i] = val
+	}
+
+	return res, nil
+}
+
+func (c *connection) SetBytes(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.key(key), value, ttl).Err()
+}
+
+// MSetBytes set หลาย key (พร้อม tag) ผ่าน pipeline
+func (c *connection) MSetBytes(ctx context.Context, items ...Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var ttls []tagTTL
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		ttls = c.setItems(ctx, pipe, items)
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	errMar := json.Unmarshal([]byte(val), &value)
-	if errMar != nil {
-		return errMar
+	return c.extendTags(ctx, ttls)
+}
+
+func (c *connection) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, c.key(key), value, ttl).Result()
+}
+
+func (c *connection) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	n, err := compareAndDeleteScript.Run(ctx, c.client, []string{c.key(key)}, value).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (c *connection) CompareAndExpire(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	n, err := compareAndExpireScript.Run(ctx, c.client, []string{c.key(key)}, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (c *connection) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, c.key(key)).Result()
+}
+
+func (c *connection) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	ok, err := c.client.Expire(ctx, c.key(key), ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMiss
 	}
 
 	return nil
 }
 
-func (c *connection) GetKeys(pattern string) ([]string, error) {
+// TTL expire time ที่เหลือ
+// redis คืน -2 เมื่อไม่มี key และ -1 เมื่อไม่หมดอายุ
+func (c *connection) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.TTL(ctx, c.key(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	switch ttl {
+	case -2:
+		return 0, ErrMiss
+	case -1:
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// Delete ลบหลาย key ผ่าน pipeline (ลบทีละ key เพื่อรองรับ cluster)
+func (c *connection) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.deleteKeys(ctx, pipe, keys)
+		return nil
+	})
+
+	return err
+}
+
+// deleteKeys queue คำสั่งลบ key ลง pipeline
+func (c *connection) deleteKeys(ctx context.Context, pipe redis.Pipeliner, keys []string) {
+	for _, key := range keys {
+		pipe.Del(ctx, c.key(key))
+	}
+}
+
+// Keys get keys ตาม pattern
+// กรณี cluster จะ scan ทุก master node
+func (c *connection) Keys(ctx context.Context, pattern string) ([]string, error) {
 	var keys []string
-	iter := c.client.Scan(c.ctx, 0, pattern, 0).Iterator()
-	for iter.Next(c.ctx) {
-		keys = append(keys, iter.Val())
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		iter := client.Scan(ctx, 0, c.key(pattern), 0).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, strings.TrimPrefix(iter.Val(), c.prefix))
+		}
+		return iter.Err()
 	}
-	if err := iter.Err(); err != nil {
-		return keys, errors.New("key does not exists")
+
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		var mu sync.Mutex
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return scan(ctx, client)
+		})
+		return keys, err
 	}
 
-	return keys, nil
+	return keys, scan(ctx, c.client)
 }
 
-func (c *connection) Delete(key string) error {
-	err := c.client.Del(c.ctx, key).Err()
+// SetWithTags set key และเพิ่ม key เข้า set ของแต่ละ tag
+func (c *connection) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	return c.MSetBytes(ctx, Item{Key: key, Value: value, TTL: ttl, Tags: tags})
+}
+
+// tagTTL expire time ที่ tag ต้องมีอย่างน้อย (<= 0 ไม่หมดอายุ)
+// cmd คือ PTTL ของ set ก่อน sadd: -2 = ยังไม่มี set, -1 = set ไม่หมดอายุ
+type tagTTL struct {
+	key string
+	ttl time.Duration
+	cmd *redis.DurationCmd
+}
+
+// setItems queue คำสั่ง set และ sadd tag ลง pipeline
+// สมาชิกของ tag เก็บเป็น key ที่ยังไม่เติม prefix
+func (c *connection) setItems(ctx context.Context, pipe redis.Pipeliner, items []Item) []tagTTL {
+	// อ่าน ttl ของ set ก่อน sadd ทุกตัวใน pipeline
+	current := make(map[string]*redis.DurationCmd)
+	for _, item := range items {
+		for _, tk := range c.keys(tagKeys(item.Tags)) {
+			if _, ok := current[tk]; !ok {
+				current[tk] = pipe.PTTL(ctx, tk)
+			}
+		}
+	}
+
+	var ttls []tagTTL
+	for _, item := range items {
+		pipe.Set(ctx, c.key(item.Key), item.Value, item.TTL)
+		for _, tk := range c.keys(tagKeys(item.Tags)) {
+			pipe.SAdd(ctx, tk, item.Key)
+			ttls = append(ttls, tagTTL{key: tk, ttl: item.TTL, cmd: current[tk]})
+		}
+	}
+
+	return ttls
+}
+
+// extendTags ต่ออายุ set ของ tag ให้อยู่นานเท่า key ที่อยู่นานที่สุด
+// ถ้ามี key ที่ไม่หมดอายุ set ของ tag ต้องไม่หมดอายุด้วย ไม่งั้น InvalidateTag จะหา key นั้นไม่เจอ
+func (c *connection) extendTags(ctx context.Context, ttls []tagTTL) error {
+	persist := make(map[string]bool)
+	extend := make(map[string]time.Duration)
+	for _, t := range ttls {
+		if t.ttl <= 0 || t.cmd.Val() == -1 {
+			persist[t.key] = true
+			continue
+		}
+		if t.cmd.Val() < t.ttl && t.ttl > extend[t.key] {
+			extend[t.key] = t.ttl
+		}
+	}
+	if len(extend) == 0 && len(persist) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for tk := range persist {
+			pipe.Persist(ctx, tk)
+		}
+		for tk, ttl := range extend {
+			if !persist[tk] {
+				pipe.PExpire(ctx, tk, ttl)
+			}
+		}
+		return nil
+	})
+
+	return err
+}
+
+// InvalidateTag ลบทุก key ที่ผูกกับ tag
+func (c *connection) InvalidateTag(ctx context.Context, tags ...string) error {
+	_, err := c.invalidateTags(ctx, tags...)
+	return err
+}
+
+// invalidateTags ลบทุก key ที่ผูกกับ tag และคืน key ที่ถูกลบ
+func (c *connection) invalidateTags(ctx context.Context, tags ...string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	keys := tagKeys(tags)
+	members := make([]*redis.StringSliceCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, tk := range keys {
+			members[i] = pipe.SMembers(ctx, c.key(tk))
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	var deleted []string
+	for _, cmd := range members {
+		deleted = append(deleted, cmd.Val()...)
+	}
+
+	return deleted, c.Delete(ctx, append(deleted, keys...)...)
 }
 
 // Close close connection
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisTagTTL(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps [][]Item // แต่ละ step คือ MSetBytes หนึ่งครั้ง
+		want  time.Duration
+	}{
+		{
+			name:  "ttl of longest member",
+			steps: [][]Item{{{Key: "a", TTL: time.Minute}, {Key: "b", TTL: time.Hour}}},
+			want:  time.Hour,
+		},
+		{
+			name:  "extend on later set",
+			steps: [][]Item{{{Key: "a", TTL: time.Minute}}, {{Key: "b", TTL: time.Hour}}},
+			want:  time.Hour,
+		},
+		{
+			name:  "shorter member keeps ttl",
+			steps: [][]Item{{{Key: "a", TTL: time.Hour}}, {{Key: "b", TTL: time.Minute}}},
+			want:  time.Hour,
+		},
+		{
+			name:  "member without ttl in same set",
+			steps: [][]Item{{{Key: "a", TTL: time.Minute}, {Key: "b"}}},
+			want:  0,
+		},
+		{
+			name:  "member without ttl first",
+			steps: [][]Item{{{Key: "a"}}, {{Key: "b", TTL: time.Minute}}},
+			want:  0,
+		},
+		{
+			name:  "member without ttl later",
+			steps: [][]Item{{{Key: "a", TTL: time.Minute}}, {{Key: "b"}}},
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			s := newTestRedis(t, mr, "reraw:").Store()
+			ctx := context.Background()
+
+			for _, items := range tt.steps {
+				for i := range items {
+					items[i].Value = []byte(items[i].Key)
+					items[i].Tags = []string{"users"}
+				}
+				err := s.MSetBytes(ctx, items...)
+				if err != nil {
+					t.Fatalf("MSetBytes: %s", err)
+				}
+			}
+
+			tagKey := "reraw:" + tagPrefix + "users"
+			if got := mr.TTL(tagKey); got != tt.want {
+				t.Fatalf("ttl of %s = %s, want %s", tagKey, got, tt.want)
+			}
+
+			// เลยเวลาของ key ที่หมดอายุทั้งหมด key ที่ไม่หมดอายุต้องยังลบผ่าน tag ได้
+			mr.FastForward(2 * time.Hour)
+			err := s.InvalidateTag(ctx, "users")
+			if err != nil {
+				t.Fatalf("InvalidateTag: %s", err)
+			}
+			for _, items := range tt.steps {
+				for _, item := range items {
+					if _, err := s.GetBytes(ctx, item.Key); err != ErrMiss {
+						t.Fatalf("GetBytes(%q) after InvalidateTag: expected ErrMiss, got %v", item.Key, err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package cache
+
+var (
+	// tagPrefix prefix ของ key ที่เก็บรายชื่อ key ใน tag
+	tagPrefix = "tag:"
+)
+
+// tagKeys แปลงชื่อ tag เป็น key ที่เก็บใน cache
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagPrefix + tag
+	}
+
+	return keys
+}
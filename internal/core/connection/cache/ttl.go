@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// MaxJitterRatio ratio สูงสุด กันค่าที่สุ่มได้ใกล้ 0
+	MaxJitterRatio = 0.5
+)
+
+// Jitter สุ่มเพิ่ม/ลด expire time ตามสัดส่วน ratio (0-0.5)
+// กัน key ที่ set พร้อมกันหมดอายุพร้อมกัน
+// ratio เกิน 0.5 จะใช้ 0.5 ผลลัพธ์จึงไม่ต่ำกว่าครึ่งหนึ่งของ d
+func Jitter(d time.Duration, ratio float64) time.Duration {
+	if d <= 0 || ratio <= 0 {
+		return d
+	}
+	if ratio > MaxJitterRatio {
+		ratio = MaxJitterRatio
+	}
+
+	delta := float64(d) * ratio
+	res := d + time.Duration((rand.Float64()*2-1)*delta)
+
+	return max(res, d/2, 1)
+}
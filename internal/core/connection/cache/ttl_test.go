@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	tests := []struct {
+		name     string
+		d        time.Duration
+		ratio    float64
+		min, max time.Duration
+	}{
+		{name: "no ratio", d: time.Minute, ratio: 0, min: time.Minute, max: time.Minute},
+		{name: "negative ratio", d: time.Minute, ratio: -1, min: time.Minute, max: time.Minute},
+		{name: "no expire", d: 0, ratio: 0.5, min: 0, max: 0},
+		{name: "small ratio", d: time.Minute, ratio: 0.1, min: 54 * time.Second, max: 66 * time.Second},
+		{name: "ratio 1 clamped", d: time.Minute, ratio: 1, min: 30 * time.Second, max: 90 * time.Second},
+		{name: "ratio above 1 clamped", d: time.Minute, ratio: 5, min: 30 * time.Second, max: 90 * time.Second},
+		{name: "tiny duration", d: 1, ratio: 1, min: 1, max: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				got := Jitter(tt.d, tt.ratio)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("Jitter(%s, %v) = %s, want %s-%s", tt.d, tt.ratio, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
@@ -1,23 +1,37 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
 	"github.com/go-resty/resty/v2"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/saveblush/reraw-api/internal/core/config"
 	"github.com/saveblush/reraw-api/internal/core/generic"
+	"github.com/saveblush/reraw-api/internal/core/metrics"
+	"github.com/saveblush/reraw-api/internal/core/tracing"
 )
 
 var (
 	contentTypeJson = "application/json"
+	headerRequestID = "X-Request-ID"
 )
 
 // Client client interface
 type Client interface {
+	WithContext(ctx context.Context) Client
 	BasicAuthentication(token string) string
 	BearerAuthentication(token string) string
 	NewHeaders(h map[string]string) map[string]string
@@ -30,12 +44,23 @@ type Client interface {
 
 type client struct {
 	session *resty.Client
+	ctx     context.Context
 }
 
 // New new client
 func New() Client {
 	return &client{
 		session: initClient(),
+		ctx:     context.Background(),
+	}
+}
+
+// WithContext with context
+// ใช้ส่ง context ของ request ต่อไปยังปลายทาง
+func (c *client) WithContext(ctx context.Context) Client {
+	return &client{
+		session: c.session,
+		ctx:     ctx,
 	}
 }
 
@@ -52,9 +77,84 @@ func initClient() *resty.Client {
 	client.SetTimeout(3 * time.Minute)
 	client.SetContentLength(true)
 
+	// request id และ tracing
+	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		if rid := requestid.FromContext(req.Context()); rid != "" {
+			req.SetHeader(headerRequestID, rid)
+		}
+		startSpan(req)
+		return nil
+	})
+
+	// metrics
+	client.OnAfterResponse(func(_ *resty.Client, res *resty.Response) error {
+		observeRequest(res.Request, strconv.Itoa(res.StatusCode()), res.Time())
+		endSpan(res.Request, res.StatusCode(), nil)
+		return nil
+	})
+	client.OnError(func(req *resty.Request, err error) {
+		// response error ถูกนับไปแล้วใน OnAfterResponse
+		var resErr *resty.ResponseError
+		if errors.As(err, &resErr) {
+			return
+		}
+		observeRequest(req, "error", time.Since(req.Time))
+		endSpan(req, 0, err)
+	})
+
 	return client
 }
 
+// observeRequest observe latency outbound request
+func observeRequest(req *resty.Request, status string, d time.Duration) {
+	var host string
+	if u, err := url.Parse(req.URL); err == nil {
+		host = u.Host
+	}
+
+	metrics.ClientRequestDuration.
+		WithLabelValues(host, req.Method, status).
+		Observe(d.Seconds())
+}
+
+// startSpan start span outbound request
+// และแนบ W3C trace header ไปกับ request
+func startSpan(req *resty.Request) {
+	var host string
+	if u, err := url.Parse(req.URL); err == nil {
+		host = u.Host
+	}
+
+	ctx, _ := tracing.Tracer().Start(req.Context(), fmt.Sprintf("%s %s", req.Method, host),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", host),
+			attribute.String("url.full", req.URL),
+		),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req.SetContext(ctx)
+}
+
+// endSpan end span outbound request
+func endSpan(req *resty.Request, status int, err error) {
+	span := trace.SpanFromContext(req.Context())
+	defer span.End()
+
+	if status > 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	if status >= 500 {
+		span.SetStatus(codes.Error, fmt.Sprintf("status %d", status))
+	}
+}
+
 // BasicAuthentication get basic token
 func (c *client) BasicAuthentication(token string) string {
 	return fmt.Sprintf("Basic %s", token)
@@ -81,6 +181,7 @@ func (c *client) NewHeaders(h map[string]string) map[string]string {
 func (c *client) get(url string, headers, queryParams map[string]string, i interface{}) (*resty.Response, error) {
 	req := c.session.
 		R().
+		SetContext(c.ctx).
 		SetHeaders(headers).
 		SetQueryParams(queryParams)
 	if i != nil {
@@ -109,6 +210,7 @@ func (c *client) delete(url string, headers, pathParams map[string]string, body
 func (c *client) setPost(headers, pathParams map[string]string, body interface{}, i interface{}) *resty.Request {
 	req := c.session.
 		R().
+		SetContext(c.ctx).
 		SetHeaders(headers).
 		SetPathParams(pathParams).
 		SetBody(body)
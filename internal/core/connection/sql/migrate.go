@@ -0,0 +1,370 @@
+package sql
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+// migrations ไฟล์ migration แยกตาม dialect
+// ชื่อไฟล์ <version>_<name>.up.sql และ <version>_<name>.down.sql
+// แต่ละ statement ต้องจบด้วย ; ท้ายบรรทัด
+//
+//go:embed migrations
+var migrations embed.FS
+
+var (
+	migrationTable   = "schema_migrations"
+	migrationLockKey = 7261676 // pg advisory lock key
+	migrationTimeout = 60      // วินาทีที่รอ lock ของ mysql
+
+	migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+// Migration migration หนึ่ง version
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus สถานะของ migration
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// schemaMigration row ของตาราง schema_migrations
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey"`
+	Name      string
+	AppliedAt int64
+}
+
+func (schemaMigration) TableName() string {
+	return migrationTable
+}
+
+// MigrateUp apply migration ที่ยังไม่ได้ apply ทั้งหมด ตามลำดับ version
+func MigrateUp(ctx context.Context, db *gorm.DB) ([]*Migration, error) {
+	list, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	err = withMigrationLock(ctx, db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range list {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				err := execStatements(tx, m.up)
+				if err != nil {
+					return err
+				}
+
+				return tx.Create(&schemaMigration{
+					Version:   m.Version,
+					Name:      m.Name,
+					AppliedAt: time.Now().Unix(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", m.Version, m.Name, err)
+			}
+			logger.Log.Infof("migration %d_%s applied", m.Version, m.Name)
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// MigrateDown rollback migration ล่าสุดตามจำนวน steps
+func MigrateDown(ctx context.Context, db *gorm.DB, steps int) ([]*Migration, error) {
+	list, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration, len(list))
+	for _, m := range list {
+		byVersion[m.Version] = m
+	}
+
+	var done []*Migration
+	err = withMigrationLock(ctx, db, func(conn *gorm.DB) error {
+		var rows []*schemaMigration
+		err := conn.Order("version desc").Limit(steps).Find(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			m, ok := byVersion[row.Version]
+			if !ok || m.down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", row.Version, row.Name)
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				err := execStatements(tx, m.down)
+				if err != nil {
+					return err
+				}
+
+				return tx.Delete(&schemaMigration{Version: m.Version}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", m.Version, m.Name, err)
+			}
+			logger.Log.Infof("migration %d_%s rolled back", m.Version, m.Name)
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// MigrateStatus สถานะของทุก migration เรียงตาม version
+func MigrateStatus(ctx context.Context, db *gorm.DB) ([]*MigrationStatus, error) {
+	list, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	db = Primary(db.WithContext(ctx))
+	err = ensureMigrationTable(db)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*MigrationStatus, len(list))
+	for i, m := range list {
+		status := &MigrationStatus{
+			Version: m.Version,
+			Name:    m.Name,
+		}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := time.Unix(row.AppliedAt, 0)
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		res[i] = status
+	}
+
+	return res, nil
+}
+
+// loadMigrations อ่าน migration ของ dialect จาก embed
+func loadMigrations(dialect string) ([]*Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migration: unsupported dialect %s", dialect)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(migrations, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration: version %d has conflicting names %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.up = string(data)
+		} else {
+			m.down = string(data)
+		}
+	}
+
+	list := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration: version %d has no up file", m.Version)
+		}
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return list, nil
+}
+
+// ensureMigrationTable สร้างตาราง schema_migrations
+func ensureMigrationTable(db *gorm.DB) error {
+	return db.Exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			version bigint NOT NULL PRIMARY KEY,
+			name varchar(255) NOT NULL,
+			applied_at bigint NOT NULL
+		)
+	`, migrationTable)).Error
+}
+
+// appliedMigrations migration ที่ apply แล้ว
+func appliedMigrations(db *gorm.DB) (map[int64]*schemaMigration, error) {
+	var rows []*schemaMigration
+	err := db.Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[int64]*schemaMigration, len(rows))
+	for _, row := range rows {
+		res[row.Version] = row
+	}
+
+	return res, nil
+}
+
+// withMigrationLock ถือ lock ระหว่าง migrate กันหลาย instance migrate พร้อมกัน
+// migration ทั้งหมดทำที่ primary
+func withMigrationLock(ctx context.Context, db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	db = Primary(db.WithContext(ctx))
+	l, ok := migrationLocks[db.Dialector.Name()]
+	if !ok {
+		err := ensureMigrationTable(db)
+		if err != nil {
+			return err
+		}
+
+		return fn(db)
+	}
+
+	// lock ผูกกับ session จึงต้อง migrate บน connection เดียวกับที่ถือ lock
+	// ใช้ transaction ตรึง connection ไว้ (dbresolver ไม่ย้าย query ที่อยู่ใน transaction)
+	// migration แต่ละ version เป็น savepoint ซ้อนอยู่ข้างใน
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	err := l.lock(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = ensureMigrationTable(tx)
+	if err == nil {
+		err = fn(tx)
+	}
+
+	// commit migration ที่สำเร็จแล้ว version ที่ล้มเหลวถูก rollback ไปที่ savepoint แล้ว
+	_ = l.unlock(tx)
+	if commitErr := tx.Commit().Error; commitErr != nil && err == nil {
+		err = commitErr
+	}
+
+	return err
+}
+
+// sessionLock คำสั่ง lock/unlock ระดับ session
+type sessionLock struct {
+	lock   func(tx *gorm.DB) error
+	unlock func(tx *gorm.DB) error
+}
+
+// migrationLocks lock ของแต่ละ dialect
+// sqlite ไม่มี เพราะ database lock ทั้งไฟล์ระหว่างเขียนอยู่แล้ว
+var migrationLocks = map[string]sessionLock{
+	PostgresDriver: {
+		lock: func(tx *gorm.DB) error {
+			return tx.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error
+		},
+		unlock: func(tx *gorm.DB) error {
+			return tx.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error
+		},
+	},
+	MysqlDriver: {
+		lock: func(tx *gorm.DB) error {
+			var ok int
+			err := tx.Raw("SELECT GET_LOCK(?, ?)", migrationTable, migrationTimeout).Scan(&ok).Error
+			if err != nil {
+				return err
+			}
+			if ok != 1 {
+				return fmt.Errorf("migration: timeout waiting for lock")
+			}
+			return nil
+		},
+		unlock: func(tx *gorm.DB) error {
+			return tx.Exec("SELECT RELEASE_LOCK(?)", migrationTable).Error
+		},
+	},
+}
+
+// execStatements exec ทีละ statement
+// driver บางตัวไม่รองรับหลาย statement ใน query เดียว
+func execStatements(db *gorm.DB, script string) error {
+	var stmt strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		stmt.WriteString(line)
+		stmt.WriteString("\n")
+
+		if !strings.HasSuffix(strings.TrimSpace(line), ";") {
+			continue
+		}
+		err := db.Exec(stmt.String()).Error
+		if err != nil {
+			return err
+		}
+		stmt.Reset()
+	}
+
+	if s := strings.TrimSpace(stmt.String()); s != "" {
+		return db.Exec(s).Error
+	}
+
+	return nil
+}
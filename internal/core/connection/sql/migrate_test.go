@@ -0,0 +1,133 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+func init() {
+	logger.InitLogger()
+}
+
+// newTestDatabase sqlite in-memory (ใช้ connection เดียว)
+func newTestDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	session, err := Open(&Configuration{DriverName: SqliteDriver})
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	t.Cleanup(func() { _ = CloseConnection(session.Database) })
+
+	return session.Database
+}
+
+func TestMigrate(t *testing.T) {
+	list, err := loadMigrations(SqliteDriver)
+	if err != nil {
+		t.Fatalf("loadMigrations: %s", err)
+	}
+	latest := list[len(list)-1]
+
+	tests := []struct {
+		name string
+		lock bool
+	}{
+		{name: "without lock", lock: false},
+		// lock ระดับ session แบบ postgres/mysql บน pool ที่มี connection เดียว ต้องไม่ deadlock
+		{name: "session lock", lock: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.lock {
+				var locked bool
+				migrationLocks[SqliteDriver] = sessionLock{
+					lock: func(tx *gorm.DB) error {
+						locked = true
+						return tx.Exec("SELECT 1").Error
+					},
+					unlock: func(tx *gorm.DB) error {
+						return tx.Exec("SELECT 1").Error
+					},
+				}
+				t.Cleanup(func() {
+					delete(migrationLocks, SqliteDriver)
+					if !locked {
+						t.Error("session lock was not used")
+					}
+				})
+			}
+
+			db := newTestDatabase(t)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			done, err := MigrateUp(ctx, db)
+			if err != nil {
+				t.Fatalf("MigrateUp: %s", err)
+			}
+			if len(done) != len(list) {
+				t.Fatalf("MigrateUp applied %d, want %d", len(done), len(list))
+			}
+			if !db.Migrator().HasTable("users") {
+				t.Fatal("table users does not exist after MigrateUp")
+			}
+
+			done, err = MigrateUp(ctx, db)
+			if err != nil || len(done) != 0 {
+				t.Fatalf("MigrateUp again = %d, %v; want 0, nil", len(done), err)
+			}
+
+			done, err = MigrateDown(ctx, db, 1)
+			if err != nil {
+				t.Fatalf("MigrateDown: %s", err)
+			}
+			if len(done) != 1 || done[0].Version != latest.Version {
+				t.Fatalf("MigrateDown rolled back %v, want version %d", done, latest.Version)
+			}
+
+			status, err := MigrateStatus(ctx, db)
+			if err != nil {
+				t.Fatalf("MigrateStatus: %s", err)
+			}
+			for i, s := range status {
+				want := i < len(status)-1
+				if s.Applied != want {
+					t.Fatalf("version %d applied = %v, want %v", s.Version, s.Applied, want)
+				}
+			}
+
+			done, err = MigrateUp(ctx, db)
+			if err != nil || len(done) != 1 {
+				t.Fatalf("MigrateUp after down = %d, %v; want 1, nil", len(done), err)
+			}
+		})
+	}
+}
+
+func TestExecStatements(t *testing.T) {
+	db := newTestDatabase(t)
+
+	err := execStatements(db, `
+-- comment
+CREATE TABLE a (
+	id integer
+);
+INSERT INTO a (id) VALUES (1);
+INSERT INTO a (id) VALUES (2)`)
+	if err != nil {
+		t.Fatalf("execStatements: %s", err)
+	}
+
+	var n int64
+	db.Table("a").Count(&n)
+	if n != 2 {
+		t.Fatalf("rows = %d, want 2", n)
+	}
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS users (
+	pubkey varchar(64) NOT NULL PRIMARY KEY,
+	created_at integer DEFAULT NULL,
+	updated_at integer DEFAULT NULL,
+	deleted_at integer DEFAULT NULL,
+	name text DEFAULT NULL,
+	lightning_url text DEFAULT NULL,
+	INDEX idx_deleted_at (deleted_at),
+	INDEX idx_name (name(191))
+);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN domain varchar(255) DEFAULT NULL, ADD INDEX idx_domain (domain);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS users (
+	pubkey varchar(64) NOT NULL PRIMARY KEY,
+	created_at integer DEFAULT NULL,
+	updated_at integer DEFAULT NULL,
+	deleted_at integer DEFAULT NULL,
+	name text DEFAULT NULL,
+	lightning_url text DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_name ON users USING gin (to_tsvector('simple', name));
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS domain varchar(255) DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_domain ON users (domain);
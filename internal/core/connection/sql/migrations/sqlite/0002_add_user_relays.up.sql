@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN relays text DEFAULT NULL;
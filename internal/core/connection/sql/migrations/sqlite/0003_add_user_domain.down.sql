@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_domain;
+
+ALTER TABLE users DROP COLUMN domain;
@@ -9,8 +9,8 @@ import (
 	"github.com/saveblush/reraw-api/internal/core/generic"
 )
 
-// openMysql open initialize a new db connection.
-func openMysql(cf *Configuration) (*gorm.DB, error) {
+// mysqlDialector dialector ของ mysql
+func mysqlDialector(cf *Configuration) (gorm.Dialector, error) {
 	if generic.IsEmpty(cf.Charset) {
 		cf.Charset = "utf8mb4"
 	}
@@ -24,5 +24,5 @@ func openMysql(cf *Configuration) (*gorm.DB, error) {
 		cf.Charset,
 	)
 
-	return gorm.Open(mysql.Open(dsn), defaultConfig)
+	return mysql.Open(dsn), nil
 }
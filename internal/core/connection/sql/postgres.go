@@ -9,8 +9,8 @@ import (
 	"github.com/saveblush/reraw-api/internal/core/utils"
 )
 
-// openPostgres open initialize a new db connection.
-func openPostgres(cf *Configuration) (*gorm.DB, error) {
+// postgresDialector dialector ของ postgres
+func postgresDialector(cf *Configuration) (gorm.Dialector, error) {
 	dsn := fmt.Sprintf("user=%s password=%s host=%s port=%d dbname=%s TimeZone=%s sslmode=disable",
 		cf.Username,
 		cf.Password,
@@ -20,8 +20,8 @@ func openPostgres(cf *Configuration) (*gorm.DB, error) {
 		utils.TimeZone(),
 	)
 
-	return gorm.Open(postgres.New(postgres.Config{
+	return postgres.New(postgres.Config{
 		DSN:                 dsn,
 		WithoutQuotingCheck: true,
-	}), defaultConfig)
+	}), nil
 }
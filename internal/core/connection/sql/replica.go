@@ -0,0 +1,255 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+var (
+	replicaHealthName           = "replica-health"
+	replicaFailoverCallback     = "replica-health:failover"
+	dbresolverCallback          = "gorm:db_resolver"
+	defaultReplicaCheckInterval = 5 * time.Second
+)
+
+// Replica read replica ค่าที่ว่างจะใช้ของ primary
+type Replica struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// Primary บังคับให้ query ไปที่ primary
+// ใช้อ่านข้อมูลทันทีหลังเขียน เพื่อไม่ให้เจอข้อมูลเก่าจาก replica ที่ยังตามไม่ทัน
+func Primary(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write).Session(&gorm.Session{})
+}
+
+// useReplicas ส่ง query อ่านไป replica ผ่าน dbresolver
+// replica ที่ ping ไม่ผ่านจะถูกข้าม ถ้าไม่เหลือจะอ่านจาก primary
+func useReplicas(db *gorm.DB, primary *sql.DB, cf *Configuration) error {
+	if cf.DriverName == SqliteDriver {
+		return errors.New("read replicas are not supported with sqlite")
+	}
+
+	dialectors := make([]gorm.Dialector, len(cf.Replicas))
+	for i, replica := range cf.Replicas {
+		dialector, err := openDialector(replicaConfiguration(cf, replica))
+		if err != nil {
+			return err
+		}
+		dialectors[i] = dialector
+	}
+
+	health, err := registerReplicas(db, primary, dialectors, cf)
+	if err != nil {
+		return err
+	}
+
+	interval := cf.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = defaultReplicaCheckInterval
+	}
+	go health.run(interval)
+
+	return nil
+}
+
+// registerReplicas ลงทะเบียน dbresolver และ plugin ตรวจสถานะ replica
+func registerReplicas(db *gorm.DB, primary *sql.DB, dialectors []gorm.Dialector, cf *Configuration) (*replicaHealth, error) {
+	health := &replicaHealth{
+		down: make(map[gorm.ConnPool]bool),
+		done: make(chan struct{}),
+	}
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   health,
+	})
+	err := db.Use(resolver)
+	if err != nil {
+		return nil, err
+	}
+	resolver.
+		SetMaxIdleConns(cf.MaxIdleConns).
+		SetMaxOpenConns(cf.MaxOpenConns).
+		SetConnMaxLifetime(cf.MaxLifetime)
+
+	// connection pool ของ replica (ไม่รวม primary)
+	_ = resolver.Call(func(pool gorm.ConnPool) error {
+		if pool != gorm.ConnPool(primary) {
+			health.replicas = append(health.replicas, pool)
+		}
+		return nil
+	})
+
+	err = db.Use(health)
+	if err != nil {
+		return nil, err
+	}
+
+	return health, nil
+}
+
+// replicaConfiguration config ของ replica จาก config ของ primary
+func replicaConfiguration(cf *Configuration, replica Replica) *Configuration {
+	c := *cf
+	c.Replicas = nil
+	c.Host = replica.Host
+	if replica.Port > 0 {
+		c.Port = replica.Port
+	}
+	if replica.Username != "" {
+		c.Username = replica.Username
+		c.Password = replica.Password
+	}
+
+	return &c
+}
+
+// closeReplicas หยุด health check และปิด connection ของ replica
+func closeReplicas(db *gorm.DB) {
+	if db == nil || db.Config == nil {
+		return
+	}
+
+	health, ok := db.Config.Plugins[replicaHealthName].(*replicaHealth)
+	if !ok {
+		return
+	}
+	health.stop()
+
+	for _, pool := range health.replicas {
+		if c, ok := pool.(*sql.DB); ok {
+			_ = c.Close()
+		}
+	}
+}
+
+// replicaHealth dbresolver policy ที่เลือก replica ที่ยังใช้งานได้แบบ round robin
+// และ gorm plugin ที่ย้าย query ไป primary เมื่อ replica ที่ถูกเลือกใช้งานไม่ได้
+type replicaHealth struct {
+	replicas []gorm.ConnPool
+	next     atomic.Uint64
+
+	mu   sync.RWMutex
+	down map[gorm.ConnPool]bool
+
+	once sync.Once
+	done chan struct{}
+}
+
+// Name implements gorm.Plugin
+func (h *replicaHealth) Name() string {
+	return replicaHealthName
+}
+
+// Initialize implements gorm.Plugin
+// dbresolver ไม่เรียก policy เมื่อมี replica ตัวเดียว จึงต้องตรวจซ้ำหลังเลือก connection
+// และต้องทำก่อน callback ที่ส่ง query จริง
+func (h *replicaHealth) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Query().After(dbresolverCallback).Before("gorm:query").Register(replicaFailoverCallback, h.failover),
+		cb.Row().After(dbresolverCallback).Before("gorm:row").Register(replicaFailoverCallback, h.failover),
+		cb.Raw().After(dbresolverCallback).Before("gorm:raw").Register(replicaFailoverCallback, h.failover),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resolve implements dbresolver.Policy
+func (h *replicaHealth) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	n := uint64(len(pools))
+	start := h.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		pool := pools[(start+i)%n]
+		if !h.isDown(pool) {
+			return pool
+		}
+	}
+
+	// ทุกตัวใช้งานไม่ได้ failover จะย้ายไป primary
+	return pools[start%n]
+}
+
+// failover ย้าย query ไป primary เมื่อ replica ที่ถูกเลือกใช้งานไม่ได้
+func (h *replicaHealth) failover(db *gorm.DB) {
+	pool := db.Statement.ConnPool
+	if p, ok := pool.(*gorm.PreparedStmtDB); ok {
+		pool = p.ConnPool
+	}
+
+	if h.isDown(pool) {
+		db.Statement.ConnPool = db.Config.ConnPool
+	}
+}
+
+func (h *replicaHealth) isDown(pool gorm.ConnPool) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.down[pool]
+}
+
+// run ping replica ทุก interval จนกว่าจะถูกหยุด
+func (h *replicaHealth) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.check(interval)
+
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check ping replica ทุกตัว และ log เมื่อสถานะเปลี่ยน
+func (h *replicaHealth) check(timeout time.Duration) {
+	for i, pool := range h.replicas {
+		pinger, ok := pool.(interface{ PingContext(context.Context) error })
+		if !ok {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := pinger.PingContext(ctx)
+		cancel()
+
+		h.mu.Lock()
+		wasDown := h.down[pool]
+		h.down[pool] = err != nil
+		h.mu.Unlock()
+
+		name := fmt.Sprintf("replica %d", i+1)
+		if err != nil && !wasDown {
+			logger.Log.Warnf("%s is unavailable, reading from other replicas or primary: %s", name, err)
+		} else if err == nil && wasDown {
+			logger.Log.Infof("%s is available again", name)
+		}
+	}
+}
+
+func (h *replicaHealth) stop() {
+	h.once.Do(func() {
+		close(h.done)
+	})
+}
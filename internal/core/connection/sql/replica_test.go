@@ -0,0 +1,113 @@
+package sql
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestReplicaDatabase sqlite ไฟล์ที่มีตาราง nodes เก็บชื่อของ database
+func newTestReplicaDatabase(t *testing.T, path, name string) gorm.Dialector {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open %s: %s", name, err)
+	}
+	conn, _ := db.DB()
+	defer conn.Close()
+
+	err = execStatements(db, "CREATE TABLE nodes (name text); INSERT INTO nodes (name) VALUES ('"+name+"')")
+	if err != nil {
+		t.Fatalf("create %s: %s", name, err)
+	}
+
+	return sqlite.Open(path)
+}
+
+func TestReplicaFailover(t *testing.T) {
+	dir := t.TempDir()
+	primaryDialector := newTestReplicaDatabase(t, filepath.Join(dir, "primary.db"), "primary")
+	replicaDialector := newTestReplicaDatabase(t, filepath.Join(dir, "replica.db"), "replica")
+
+	db, err := gorm.Open(primaryDialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open primary: %s", err)
+	}
+	primary, _ := db.DB()
+	t.Cleanup(func() {
+		closeReplicas(db)
+		_ = primary.Close()
+	})
+
+	health, err := registerReplicas(db, primary, []gorm.Dialector{replicaDialector}, &Configuration{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("registerReplicas: %s", err)
+	}
+	if len(health.replicas) != 1 {
+		t.Fatalf("replicas = %d, want 1", len(health.replicas))
+	}
+
+	queries := []struct {
+		name string
+		run  func(db *gorm.DB) (string, error)
+	}{
+		{
+			name: "query",
+			run: func(db *gorm.DB) (string, error) {
+				var names []string
+				err := db.Table("nodes").Pluck("name", &names).Error
+				if len(names) == 0 {
+					return "", err
+				}
+				return names[0], err
+			},
+		},
+		{
+			name: "row",
+			run: func(db *gorm.DB) (string, error) {
+				var name string
+				err := db.Table("nodes").Select("name").Row().Scan(&name)
+				return name, err
+			},
+		},
+		{
+			name: "raw",
+			run: func(db *gorm.DB) (string, error) {
+				var name sql.NullString
+				err := db.Raw("SELECT name FROM nodes").Scan(&name).Error
+				return name.String, err
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		down bool
+		want string
+	}{
+		{name: "replica up", down: false, want: "replica"},
+		{name: "replica down", down: true, want: "primary"},
+	}
+
+	for _, tt := range tests {
+		health.mu.Lock()
+		health.down[health.replicas[0]] = tt.down
+		health.mu.Unlock()
+
+		for _, q := range queries {
+			t.Run(tt.name+"/"+q.name, func(t *testing.T) {
+				got, err := q.run(db)
+				if err != nil {
+					t.Fatalf("%s: %s", q.name, err)
+				}
+				if got != tt.want {
+					t.Fatalf("%s read from %q, want %q", q.name, got, tt.want)
+				}
+			})
+		}
+	}
+}
@@ -1,15 +1,21 @@
 package sql
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	log "github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/core/utils/retry"
 )
 
 var (
 	MysqlDriver    = "mysql"
 	PostgresDriver = "postgres"
+	SqliteDriver   = "sqlite"
 )
 
 var (
@@ -49,33 +55,76 @@ type Configuration struct {
 	MaxIdleConns int
 	MaxOpenConns int
 	MaxLifetime  time.Duration
+	Retry        retry.Policy
+
+	// read replica ถ้ามีจะส่ง query อ่านไป replica และเขียนไป primary
+	Replicas             []Replica
+	ReplicaCheckInterval time.Duration
 }
 
-// InitConnectionMysql open initialize a new db connection.
+// ErrNotConnected database is not connected
+var ErrNotConnected = errors.New("database is not connected")
+
+// InitConnection open initialize a new db connection.
+// ping ซ้ำตาม retry policy จนกว่าจะเชื่อมต่อได้
 func InitConnection(cf *Configuration) (*Session, error) {
-	var db *gorm.DB
-	var err error
+	session, err := Open(cf)
+	if err != nil {
+		return nil, err
+	}
 
-	if cf.DriverName == PostgresDriver {
-		db, err = openPostgres(cf)
-	} else {
-		db, err = openMysql(cf)
+	err = retry.Do(context.Background(), cf.Retry, func(attempt int) error {
+		err := Ping(context.Background(), session.Database)
+		if err != nil {
+			log.Log.Warnf("ping database error (attempt %d/%d): %s", attempt, cf.Retry.Attempts, err)
+		}
+		return err
+	})
+	if err != nil {
+		_ = CloseConnection(session.Database)
+		return nil, err
 	}
+
+	return session, nil
+}
+
+// Open open db connection pool without ping
+// connection จริงจะถูกสร้างเมื่อมีการใช้งาน
+func Open(cf *Configuration) (*Session, error) {
+	dialector, err := openDialector(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, defaultConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// tracing
+	err = db.Use(tracingPlugin{})
 	if err != nil {
 		return nil, err
 	}
 
 	// set config connection pool
-	if cf.MaxIdleConns > 0 {
+	if cf.MaxIdleConns <= 0 {
 		cf.MaxIdleConns = defaultMaxIdleConns
 	}
-	if cf.MaxOpenConns > 0 {
+	if cf.MaxOpenConns <= 0 {
 		cf.MaxOpenConns = defaultMaxOpenConns
 	}
-	if cf.MaxLifetime > 0 {
+	if cf.MaxLifetime <= 0 {
 		cf.MaxLifetime = defaultMaxLifetime
 	}
 
+	// sqlite memory database แยกกันต่อ connection จึงต้องใช้ connection เดียวตลอด
+	if cf.DriverName == SqliteDriver && sqliteInMemory(cf) {
+		cf.MaxIdleConns = 1
+		cf.MaxOpenConns = 1
+		cf.MaxLifetime = 0
+	}
+
 	// connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -86,16 +135,49 @@ func InitConnection(cf *Configuration) (*Session, error) {
 	sqlDB.SetMaxOpenConns(cf.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(cf.MaxLifetime)
 
-	err = sqlDB.Ping()
-	if err != nil {
-		return nil, err
+	// read replica
+	if len(cf.Replicas) > 0 {
+		err = useReplicas(db, sqlDB, cf)
+		if err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
 	}
 
 	return &Session{Database: db}, nil
 }
 
+// openDialector dialector ตาม driver
+func openDialector(cf *Configuration) (gorm.Dialector, error) {
+	switch cf.DriverName {
+	case PostgresDriver:
+		return postgresDialector(cf)
+	case SqliteDriver:
+		return sqliteDialector(cf)
+	default:
+		return mysqlDialector(cf)
+	}
+}
+
+// Ping ping db connection
+func Ping(ctx context.Context, db *gorm.DB) error {
+	if db == nil || db.Config == nil {
+		return ErrNotConnected
+	}
+
+	c, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return c.PingContext(ctx)
+}
+
 // CloseConnection close connection db
+// รวม connection ของ read replica
 func CloseConnection(db *gorm.DB) error {
+	closeReplicas(db)
+
 	c, err := db.DB()
 	if err != nil {
 		return err
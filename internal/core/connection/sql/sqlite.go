@@ -0,0 +1,17 @@
+package sql
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteDialector dialector ของ sqlite
+// ใช้ driver pure go ไม่ต้องใช้ cgo
+func sqliteDialector(cf *Configuration) (gorm.Dialector, error) {
+	dsn, err := sqliteDSN(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlite.Open(dsn), nil
+}
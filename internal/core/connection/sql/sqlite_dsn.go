@@ -0,0 +1,34 @@
+package sql
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	sqliteMemory = ":memory:"
+
+	// busy_timeout รอ lock แทนการคืน SQLITE_BUSY ทันที
+	// WAL ให้อ่านพร้อมกับเขียนได้
+	sqlitePragmas = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+)
+
+// sqliteDSN dsn ของ sqlite จาก DATABASE_NAME (path ของไฟล์ หรือ :memory:)
+func sqliteDSN(cf *Configuration) (string, error) {
+	if sqliteInMemory(cf) {
+		return fmt.Sprintf("file::memory:?%s", sqlitePragmas), nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(cf.DatabaseName), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("file:%s?%s", cf.DatabaseName, sqlitePragmas), nil
+}
+
+// sqliteInMemory DATABASE_NAME ว่างหรือ :memory: = memory database
+func sqliteInMemory(cf *Configuration) bool {
+	return cf.DatabaseName == "" || cf.DatabaseName == sqliteMemory
+}
@@ -0,0 +1,85 @@
+package sql
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/saveblush/reraw-api/internal/core/tracing"
+)
+
+var (
+	tracingSpanKey       = "tracing:span"
+	tracingCallbackStart = "tracing:before"
+	tracingCallbackEnd   = "tracing:after"
+)
+
+// tracingPlugin gorm plugin สร้าง child span ต่อ query
+type tracingPlugin struct{}
+
+// Name implements gorm.Plugin
+func (tracingPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize implements gorm.Plugin
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register(tracingCallbackStart, p.before("create")),
+		cb.Create().After("gorm:create").Register(tracingCallbackEnd, p.after),
+		cb.Query().Before("gorm:query").Register(tracingCallbackStart, p.before("select")),
+		cb.Query().After("gorm:query").Register(tracingCallbackEnd, p.after),
+		cb.Update().Before("gorm:update").Register(tracingCallbackStart, p.before("update")),
+		cb.Update().After("gorm:update").Register(tracingCallbackEnd, p.after),
+		cb.Delete().Before("gorm:delete").Register(tracingCallbackStart, p.before("delete")),
+		cb.Delete().After("gorm:delete").Register(tracingCallbackEnd, p.after),
+		cb.Row().Before("gorm:row").Register(tracingCallbackStart, p.before("row")),
+		cb.Row().After("gorm:row").Register(tracingCallbackEnd, p.after),
+		cb.Raw().Before("gorm:raw").Register(tracingCallbackStart, p.before("raw")),
+		cb.Raw().After("gorm:raw").Register(tracingCallbackEnd, p.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := tracing.Tracer().Start(db.Statement.Context, "gorm "+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", db.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func (tracingPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.collection.name", db.Statement.Table),
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.response.returned_rows", db.RowsAffected),
+	)
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
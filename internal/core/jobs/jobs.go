@@ -0,0 +1,385 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/connection/cache/lock"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+var (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	historyKey   = "job-history:%s"
+	localHistory = "job-history:%s:%s" // job local แยกประวัติตามเครื่อง
+	lockName     = "job:%s"
+	electionName = "jobs"
+
+	defaultLockTTL     = time.Minute
+	defaultElectionTTL = 15 * time.Second
+)
+
+var (
+	// ErrNotFound ไม่มี job ตามชื่อ
+	ErrNotFound = errors.New("jobs: job not found")
+
+	// ErrRunning job กำลังทำงานอยู่
+	ErrRunning = errors.New("jobs: job is already running")
+
+	// ErrNotInitialized ยังไม่ได้เรียก Init
+	ErrNotInitialized = errors.New("jobs: scheduler is not initialized")
+
+	// ErrNoLock store ของ scheduler ถือ lock ข้าม instance ไม่ได้ (noop)
+	ErrNoLock = errors.New("jobs: shared jobs need a cache store with locking, noop is not exclusive")
+)
+
+// Func งานที่ต้องทำ ต้องหยุดเมื่อ context ถูกยกเลิก
+type Func func(ctx context.Context) error
+
+// Job งานที่ทำเป็นรอบ
+type Job struct {
+	Name     string
+	Schedule string        // cron 5 ตัว หรือ @every 1m, @hourly
+	Timeout  time.Duration // 0 = ไม่จำกัด
+	Local    bool          // true = ทำทุก instance เช่นงานที่เขียนไฟล์ในเครื่อง
+	Run      Func
+}
+
+// Result ผลการทำงานแต่ละรอบ
+type Result struct {
+	Instance   string    `json:"instance"`
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Status สถานะของ job
+type Status struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Local    bool       `json:"local"`
+	Running  bool       `json:"running"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *Result    `json:"last_run,omitempty"`
+}
+
+// Configuration config scheduler
+type Configuration struct {
+	LockTTL     time.Duration // อายุ lock ของแต่ละ job (ต่ออายุระหว่างทำงาน)
+	ElectionTTL time.Duration // อายุ lease ของ leader
+}
+
+// entry job ที่ลงทะเบียนแล้ว
+type entry struct {
+	job     *Job
+	id      cron.EntryID
+	mutex   *lock.Mutex
+	running atomic.Bool
+}
+
+// scheduler ตัวจัดการ job
+// job ที่ไม่ใช่ local จะทำเฉพาะ instance ที่เป็น leader และต้องถือ lock ของ job
+type scheduler struct {
+	mu       sync.Mutex
+	cron     *cron.Cron
+	store    cache.Store
+	election *lock.Election
+	entries  map[string]*entry
+	lockTTL  time.Duration
+	noLock   bool // store เป็น noop รับเฉพาะ job local
+	hostname string
+	instance string
+	stopped  bool // ถือ mu ก่อนอ่าน/เขียน
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+var current *scheduler
+
+// Init init scheduler
+// store noop รับได้เฉพาะ job local เพราะ lock ไม่ exclusive
+func Init(store cache.Store, cf *Configuration) {
+	current = newScheduler(store, cf)
+}
+
+// newScheduler new scheduler
+func newScheduler(store cache.Store, cf *Configuration) *scheduler {
+	lockTTL := cf.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = defaultLockTTL
+	}
+	electionTTL := cf.ElectionTTL
+	if electionTTL <= 0 {
+		electionTTL = defaultElectionTTL
+	}
+
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &scheduler{
+		cron:     cron.New(),
+		store:    store,
+		election: lock.NewElection(store, electionName, electionTTL),
+		entries:  make(map[string]*entry),
+		lockTTL:  lockTTL,
+		noLock:   cache.IsNoop(store),
+		hostname: hostname,
+		instance: fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Register register job
+// schedule ว่างจะไม่ทำตามรอบ สั่งได้จาก Trigger เท่านั้น
+func Register(job *Job) error {
+	s := current
+	if s == nil {
+		return ErrNotInitialized
+	}
+
+	return s.register(job)
+}
+
+func (s *scheduler) register(job *Job) error {
+	if !job.Local && s.noLock {
+		return fmt.Errorf("%w: %s", ErrNoLock, job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("jobs: job %s already registered", job.Name)
+	}
+
+	e := &entry{
+		job:   job,
+		mutex: lock.New(s.store, fmt.Sprintf(lockName, job.Name), s.lockTTL),
+	}
+	if job.Schedule != "" {
+		id, err := s.cron.AddFunc(job.Schedule, func() {
+			s.run(e, TriggerSchedule)
+		})
+		if err != nil {
+			return fmt.Errorf("jobs: invalid schedule of %s: %w", job.Name, err)
+		}
+		e.id = id
+	}
+	s.entries[job.Name] = e
+
+	return nil
+}
+
+// Start เริ่มทำ job ตามรอบ และลงสมัคร leader
+func Start() {
+	s := current
+	if s == nil {
+		return
+	}
+
+	go s.election.Run(s.ctx)
+	s.cron.Start()
+	logger.Log.Infof("job scheduler started with %d jobs", len(s.entries))
+}
+
+// Stop หยุดรับรอบใหม่ ยกเลิก job ที่กำลังทำ และรอจนจบหรือ context หมดเวลา
+func Stop(ctx context.Context) error {
+	s := current
+	if s == nil {
+		return nil
+	}
+
+	// run ที่เริ่มหลังจากนี้จะไม่ทำงาน wg.Wait จึงไม่ชนกับ wg.Add
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.cron.Stop()
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// List สถานะของทุก job เรียงตามชื่อ
+func List(ctx context.Context) ([]*Status, error) {
+	s := current
+	if s == nil {
+		return nil, ErrNotInitialized
+	}
+
+	s.mu.Lock()
+	entries := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].job.Name < entries[j].job.Name
+	})
+
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		keys[i] = s.historyKey(e.job)
+	}
+	history, err := cache.MGet[Result](ctx, s.store, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Status, len(entries))
+	for i, e := range entries {
+		status := &Status{
+			Name:     e.job.Name,
+			Schedule: e.job.Schedule,
+			Local:    e.job.Local,
+			Running:  e.running.Load(),
+		}
+		if e.id != 0 {
+			if next := s.cron.Entry(e.id).Next; !next.IsZero() {
+				status.NextRun = &next
+			}
+		}
+		if r, ok := history[keys[i]]; ok {
+			status.LastRun = &r
+		}
+		res[i] = status
+	}
+
+	return res, nil
+}
+
+// Trigger สั่งทำ job ทันทีแบบ background
+func Trigger(name string) error {
+	s := current
+	if s == nil {
+		return ErrNotInitialized
+	}
+
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+	if e.running.Load() {
+		return ErrRunning
+	}
+
+	go s.run(e, TriggerManual)
+
+	return nil
+}
+
+// run ทำ job หนึ่งรอบ
+func (s *scheduler) run(e *entry, trigger string) {
+	name := e.job.Name
+	if !e.job.Local && trigger == TriggerSchedule && !s.election.IsLeader() {
+		return
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.wg.Add(1)
+	s.mu.Unlock()
+	defer s.wg.Done()
+
+	if !e.running.CompareAndSwap(false, true) {
+		logger.Log.Warnf("job %s is still running, skipped", name)
+		return
+	}
+	defer e.running.Store(false)
+
+	ctx := s.ctx
+	if !e.job.Local {
+		ok, err := e.mutex.TryLock(ctx)
+		if err != nil {
+			logger.Log.Errorf("lock job %s error: %s", name, err)
+			return
+		}
+		if !ok {
+			logger.Log.Infof("job %s is running on another instance, skipped", name)
+			return
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = e.mutex.KeepAlive(ctx)
+		defer func() {
+			cancel()
+			_ = e.mutex.Unlock(context.WithoutCancel(ctx))
+		}()
+	}
+
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := call(ctx, e.job.Run)
+	result := &Result{
+		Instance:   s.instance,
+		Trigger:    trigger,
+		StartedAt:  start,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		logger.Log.Errorf("job %s failed after %s: %s", name, time.Since(start), err)
+	} else {
+		logger.Log.Infof("job %s finished in %s", name, time.Since(start))
+	}
+
+	err = cache.Set(context.WithoutCancel(ctx), s.store, s.historyKey(e.job), result, 0)
+	if err != nil {
+		logger.Log.Warnf("save job %s history error: %s", name, err)
+	}
+}
+
+// historyKey key ของผลการทำงานล่าสุด
+// job local ทำทุกเครื่อง จึงแยก key ตาม hostname ไม่ให้เขียนทับกัน
+func (s *scheduler) historyKey(job *Job) string {
+	if job.Local {
+		return fmt.Sprintf(localHistory, job.Name, s.hostname)
+	}
+
+	return fmt.Sprintf(historyKey, job.Name)
+}
+
+// call เรียก job และแปลง panic เป็น error
+func call(ctx context.Context, fn Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn(ctx)
+}
@@ -0,0 +1,306 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+func init() {
+	logger.InitLogger()
+}
+
+// newTestScheduler init scheduler บน memory store
+func newTestScheduler(t *testing.T) *scheduler {
+	t.Helper()
+
+	s, err := cache.Open(&cache.Configuration{Driver: cache.DriverMemory})
+	if err != nil {
+		t.Fatalf("open cache: %s", err)
+	}
+	Init(s.Store(), &Configuration{})
+	t.Cleanup(func() {
+		_ = Stop(context.Background())
+		_ = s.Close()
+	})
+
+	return current
+}
+
+// waitFor รอจนเงื่อนไขเป็นจริง
+func waitFor(t *testing.T, msg string, fn func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHistoryKey(t *testing.T) {
+	s := newTestScheduler(t)
+
+	tests := []struct {
+		name string
+		job  *Job
+		want string
+	}{
+		{name: "shared", job: &Job{Name: "export"}, want: "job-history:export"},
+		{name: "local", job: &Job{Name: "snapshot", Local: true}, want: fmt.Sprintf("job-history:snapshot:%s", s.hostname)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.historyKey(tt.job); got != tt.want {
+				t.Fatalf("historyKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	tests := []struct {
+		name      string
+		local     bool
+		err       error
+		wantError string
+	}{
+		{name: "shared", local: false},
+		{name: "local", local: true},
+		{name: "failed", local: true, err: errors.New("boom"), wantError: "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestScheduler(t)
+
+			var runs atomic.Int32
+			err := Register(&Job{
+				Name:  "test",
+				Local: tt.local,
+				Run: func(ctx context.Context) error {
+					runs.Add(1)
+					return tt.err
+				},
+			})
+			if err != nil {
+				t.Fatalf("Register: %s", err)
+			}
+
+			if err := Trigger("missing"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Trigger(missing) = %v, want ErrNotFound", err)
+			}
+			if err := Trigger("test"); err != nil {
+				t.Fatalf("Trigger: %s", err)
+			}
+
+			var last *Result
+			waitFor(t, "job history", func() bool {
+				list, err := List(context.Background())
+				if err != nil || len(list) != 1 {
+					return false
+				}
+				last = list[0].LastRun
+				return last != nil
+			})
+			if runs.Load() != 1 {
+				t.Fatalf("runs = %d, want 1", runs.Load())
+			}
+			if last.Trigger != TriggerManual || last.Error != tt.wantError {
+				t.Fatalf("last run = %+v, want trigger %s error %q", last, TriggerManual, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestStopWaitsRunningJob(t *testing.T) {
+	newTestScheduler(t)
+
+	var canceled atomic.Bool
+	started := make(chan struct{})
+	err := Register(&Job{
+		Name:  "wait",
+		Local: true,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			canceled.Store(true)
+			return ctx.Err()
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register: %s", err)
+	}
+
+	_ = Trigger("wait")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := Stop(ctx); err != nil {
+		t.Fatalf("Stop: %s", err)
+	}
+	if !canceled.Load() {
+		t.Fatal("Stop returned before running job finished")
+	}
+}
+
+func TestRunAfterStop(t *testing.T) {
+	s := newTestScheduler(t)
+
+	var runs atomic.Int32
+	err := Register(&Job{
+		Name:  "late",
+		Local: true,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register: %s", err)
+	}
+
+	if err := Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %s", err)
+	}
+
+	// รอบที่เริ่มหลัง Stop ต้องไม่ทำงาน
+	s.run(s.entries["late"], TriggerSchedule)
+	if runs.Load() != 0 {
+		t.Fatalf("runs = %d after Stop, want 0", runs.Load())
+	}
+}
+
+func TestRegisterSharedJobOnNoop(t *testing.T) {
+	s, err := cache.Open(&cache.Configuration{Driver: cache.DriverNoop})
+	if err != nil {
+		t.Fatalf("open cache: %s", err)
+	}
+	sch := newScheduler(s.Store(), &Configuration{})
+
+	tests := []struct {
+		name    string
+		local   bool
+		wantErr error
+	}{
+		{name: "local", local: true},
+		{name: "shared", local: false, wantErr: ErrNoLock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sch.register(&Job{Name: tt.name, Local: tt.local, Run: func(context.Context) error { return nil }})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("register = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newRedisScheduler scheduler บน redis (miniredis) แยก connection ต่อ instance
+func newRedisScheduler(t *testing.T, mr *miniredis.Miniredis) *scheduler {
+	t.Helper()
+
+	s, err := cache.Open(&cache.Configuration{Addrs: []string{mr.Addr()}})
+	if err != nil {
+		t.Fatalf("open cache: %s", err)
+	}
+	sch := newScheduler(s.Store(), &Configuration{ElectionTTL: time.Second})
+	t.Cleanup(func() {
+		sch.cancel()
+		sch.wg.Wait()
+		_ = s.Close()
+	})
+
+	return sch
+}
+
+func TestSharedJob(t *testing.T) {
+	mr := miniredis.RunT(t)
+	leader := newRedisScheduler(t, mr)
+	follower := newRedisScheduler(t, mr)
+
+	var runs atomic.Int32
+	var once sync.Once
+	release := make(chan struct{})
+	unblock := func() { once.Do(func() { close(release) }) }
+	t.Cleanup(unblock)
+	started := make(chan struct{}, 2)
+	for _, s := range []*scheduler{leader, follower} {
+		err := s.register(&Job{
+			Name: "shared",
+			Run: func(ctx context.Context) error {
+				runs.Add(1)
+				started <- struct{}{}
+				<-release
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("register: %s", err)
+		}
+	}
+
+	go leader.election.Run(leader.ctx)
+	waitFor(t, "leader", leader.election.IsLeader)
+	go follower.election.Run(follower.ctx)
+
+	// รอบตาม schedule ทำเฉพาะ leader
+	follower.run(follower.entries["shared"], TriggerSchedule)
+	if runs.Load() != 0 {
+		t.Fatalf("runs = %d after follower schedule, want 0", runs.Load())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		leader.run(leader.entries["shared"], TriggerSchedule)
+		close(done)
+	}()
+	<-started
+
+	// lock ของ job ถูกถืออยู่ instance อื่นต้องข้าม แม้สั่งเอง
+	skipped := make(chan struct{})
+	go func() {
+		follower.run(follower.entries["shared"], TriggerManual)
+		close(skipped)
+	}()
+	select {
+	case <-skipped:
+	case <-started:
+		t.Fatal("follower ran the job while the leader held the lock")
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for follower run")
+	}
+
+	unblock()
+	<-done
+
+	// ปล่อย lock แล้ว instance อื่นทำได้
+	follower.run(follower.entries["shared"], TriggerManual)
+	<-started
+	if runs.Load() != 2 {
+		t.Fatalf("runs = %d after unlock, want 2", runs.Load())
+	}
+
+	last, err := cache.Get[Result](context.Background(), follower.store, "job-history:shared")
+	if err != nil {
+		t.Fatalf("get history: %s", err)
+	}
+	if last.Trigger != TriggerManual {
+		t.Fatalf("last run trigger = %s, want %s", last.Trigger, TriggerManual)
+	}
+}
@@ -0,0 +1,61 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+// Hook hook ที่จะถูกเรียกตอน shutdown
+type Hook func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   Hook
+}
+
+var (
+	mu       sync.Mutex
+	hooks    []hook
+	stopping atomic.Bool
+)
+
+// OnStop register hook หยุด background worker/watcher
+// hook จะถูกเรียกย้อนลำดับการ register
+func OnStop(name string, fn Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	hooks = append(hooks, hook{name: name, fn: fn})
+}
+
+// Stopping is shutting down
+func Stopping() bool {
+	return stopping.Load()
+}
+
+// BeginShutdown mark app as shutting down
+func BeginShutdown() {
+	stopping.Store(true)
+}
+
+// Stop run all stop hooks
+func Stop(ctx context.Context) {
+	BeginShutdown()
+
+	mu.Lock()
+	list := hooks
+	hooks = nil
+	mu.Unlock()
+
+	for i := len(list) - 1; i >= 0; i-- {
+		h := list[i]
+		if err := h.fn(ctx); err != nil {
+			logger.Log.Errorf("stop %s error: %s", h.name, err)
+			continue
+		}
+		logger.Log.Infof("%s stopped", h.name)
+	}
+}
@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	namespace = "reraw"
+)
+
+var (
+	// Registry registry เก็บ metrics ทั้งหมดของระบบ
+	Registry = prometheus.NewRegistry()
+
+	// HTTPRequestsTotal จำนวน request แยกตาม route
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration latency ของ request แยกตาม route
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// CacheRequestsTotal จำนวน cache hit/miss
+	CacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Total number of cache lookups by result (hit, local_hit, miss, error).",
+	}, []string{"result"})
+
+	// ClientRequestDuration latency ของ request ที่ยิงออกไปภายนอก
+	ClientRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "client",
+		Name:      "request_duration_seconds",
+		Help:      "Outbound HTTP request latency by host, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "method", "status"})
+)
+
+var (
+	cacheHit      = "hit"
+	cacheLocalHit = "local_hit"
+	cacheMiss     = "miss"
+	cacheError    = "error"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		CacheRequestsTotal,
+		ClientRequestDuration,
+		newBreakerCollector(),
+	)
+}
+
+// Handler http handler สำหรับ scrape metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		Registry: Registry,
+	})
+}
+
+// CacheHit นับ cache hit
+func CacheHit() {
+	CacheRequestsTotal.WithLabelValues(cacheHit).Inc()
+}
+
+// CacheLocalHit นับ cache hit จาก local cache
+func CacheLocalHit() {
+	CacheRequestsTotal.WithLabelValues(cacheLocalHit).Inc()
+}
+
+// CacheMiss นับ cache miss
+func CacheMiss() {
+	CacheRequestsTotal.WithLabelValues(cacheMiss).Inc()
+}
+
+// CacheError นับ cache error
+func CacheError() {
+	CacheRequestsTotal.WithLabelValues(cacheError).Inc()
+}
+
+// RegisterDatabase register gauges connection pool ของ database
+func RegisterDatabase(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedis register gauges connection pool ของ redis
+func RegisterRedis(fn func() *redis.PoolStats) error {
+	return Registry.Register(newRedisCollector(fn))
+}
+
+// redisCollector collector redis pool stats
+type redisCollector struct {
+	stats      func() *redis.PoolStats
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+func newRedisCollector(fn func() *redis.PoolStats) *redisCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, nil)
+	}
+
+	return &redisCollector{
+		stats:      fn,
+		hits:       desc("hits_total", "Number of times a free connection was found in the pool."),
+		misses:     desc("misses_total", "Number of times a free connection was not found in the pool."),
+		timeouts:   desc("timeouts_total", "Number of times a wait timeout occurred."),
+		totalConns: desc("total_connections", "Number of total connections in the pool."),
+		idleConns:  desc("idle_connections", "Number of idle connections in the pool."),
+		staleConns: desc("stale_connections_total", "Number of stale connections removed from the pool."),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (rc *redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rc.hits
+	ch <- rc.misses
+	ch <- rc.timeouts
+	ch <- rc.totalConns
+	ch <- rc.idleConns
+	ch <- rc.staleConns
+}
+
+// Collect implements prometheus.Collector
+func (rc *redisCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := rc.stats()
+	if stats == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(rc.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(rc.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(rc.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(rc.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(rc.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(rc.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
+
+// breakerCollector collector สถานะ circuit breaker
+type breakerCollector struct {
+	open *prometheus.Desc
+}
+
+func newBreakerCollector() *breakerCollector {
+	return &breakerCollector{
+		open: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "breaker", "open"),
+			"Whether the circuit breaker is open (1) or closed (0).",
+			[]string{"name"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (bc *breakerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bc.open
+}
+
+// Collect implements prometheus.Collector
+func (bc *breakerCollector) Collect(ch chan<- prometheus.Metric) {
+	for name := range hystrix.GetCircuitSettings() {
+		cb, _, err := hystrix.GetCircuit(name)
+		if err != nil {
+			continue
+		}
+
+		var v float64
+		if cb.IsOpen() {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(bc.open, prometheus.GaugeValue, v, name)
+	}
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterNone   = "none"
+)
+
+var (
+	// TracerName ชื่อ tracer ของระบบ
+	TracerName = "github.com/saveblush/reraw-api"
+
+	defaultShutdownTimeout = 5 * time.Second
+)
+
+var (
+	provider *sdktrace.TracerProvider
+
+	// output ไฟล์ของ file exporter ปิดเมื่อ Close
+	output *os.File
+)
+
+// Configuration config tracing
+type Configuration struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	Exporter       string
+	Endpoint       string
+	Insecure       bool
+	FilePath       string
+	SampleRatio    *float64 // nil = 1, 0 = ไม่เก็บ trace ที่เริ่มเอง (ยังเก็บตาม parent)
+}
+
+// Init init tracer provider
+func Init(cf *Configuration) error {
+	// propagator W3C trace context
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(cf)
+	if err != nil {
+		return err
+	}
+	if exporter == nil {
+		return nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cf.ServiceName),
+		semconv.ServiceVersion(cf.ServiceVersion),
+		semconv.DeploymentEnvironment(cf.Environment),
+	))
+	if err != nil {
+		closeOutput()
+		return err
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(newSampler(cf.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return nil
+}
+
+// newSampler sampler ของ trace ที่เริ่มจากระบบเอง
+// ไม่กำหนด = เก็บทุก trace, <= 0 = ไม่เก็บ
+func newSampler(ratio *float64) sdktrace.Sampler {
+	switch {
+	case ratio == nil || *ratio >= 1:
+		return sdktrace.AlwaysSample()
+	case *ratio <= 0:
+		return sdktrace.NeverSample()
+	}
+
+	return sdktrace.TraceIDRatioBased(*ratio)
+}
+
+// newExporter new span exporter
+func newExporter(cf *Configuration) (sdktrace.SpanExporter, error) {
+	switch cf.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cf.Endpoint),
+		}
+		if cf.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+	case ExporterFile:
+		f, err := os.OpenFile(cf.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		output = f
+		return stdouttrace.New(stdouttrace.WithWriter(f))
+
+	case ExporterNone, "":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown tracing exporter: %s", cf.Exporter)
+}
+
+// Tracer get tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// TraceID get trace id from context
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+
+	return sc.TraceID().String()
+}
+
+// Close flush and close tracer provider และไฟล์ของ file exporter
+func Close() error {
+	if provider == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+
+	err := provider.Shutdown(ctx)
+	closeOutput()
+
+	return err
+}
+
+// closeOutput ปิดไฟล์ของ file exporter
+func closeOutput() {
+	if output != nil {
+		_ = output.Close()
+		output = nil
+	}
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNewSampler(t *testing.T) {
+	ratio := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name  string
+		ratio *float64
+		want  string
+	}{
+		{name: "unset", ratio: nil, want: sdktrace.AlwaysSample().Description()},
+		{name: "zero", ratio: ratio(0), want: sdktrace.NeverSample().Description()},
+		{name: "negative", ratio: ratio(-1), want: sdktrace.NeverSample().Description()},
+		{name: "half", ratio: ratio(0.5), want: sdktrace.TraceIDRatioBased(0.5).Description()},
+		{name: "one", ratio: ratio(1), want: sdktrace.AlwaysSample().Description()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newSampler(tt.ratio).Description(); got != tt.want {
+				t.Fatalf("newSampler = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloseFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	err := Init(&Configuration{Exporter: ExporterFile, FilePath: path})
+	if err != nil {
+		t.Fatalf("Init: %s", err)
+	}
+
+	f := output
+	if f == nil {
+		t.Fatal("file exporter output is nil")
+	}
+	if err := Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	// ไฟล์ต้องถูกปิดแล้ว
+	if _, err := f.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("write after Close = %v, want os.ErrClosed", err)
+	}
+	if output != nil {
+		t.Fatal("output is not reset after Close")
+	}
+}
@@ -1,31 +1,175 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"path/filepath"
+	"sync"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var Log *zap.SugaredLogger
+var (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+	OutputStdout    = "stdout"
+	OutputStderr    = "stderr"
+)
+
+var (
+	Log *zap.SugaredLogger
+
+	// Level level ของ log ปรับได้ขณะ runtime
+	Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+)
+
+var (
+	mu      sync.Mutex
+	closers []io.Closer
+)
+
+// Configuration config logger
+type Configuration struct {
+	Level        string
+	Encoding     string
+	Outputs      []string // stdout, stderr หรือ path ของไฟล์
+	ErrorOutputs []string // sink แยกสำหรับ error ขึ้นไป
+	Rotation     Rotation
+}
+
+// Rotation config rotate log file by size
+type Rotation struct {
+	MaxSize    int // megabytes
+	MaxBackups int
+	MaxAge     int // days
+	Compress   bool
+}
+
+// defaultConfiguration ใช้ก่อนอ่าน config
+var defaultConfiguration = &Configuration{
+	Level:        zapcore.InfoLevel.String(),
+	Encoding:     EncodingJSON,
+	Outputs:      []string{OutputStdout},
+	ErrorOutputs: []string{OutputStderr},
+}
 
 // InitLogger init logger
 func InitLogger() {
+	_ = Configure(defaultConfiguration)
+}
+
+// Configure build logger from configuration
+func Configure(cf *Configuration) error {
+	if cf.Level != "" {
+		lvl, err := zapcore.ParseLevel(cf.Level)
+		if err != nil {
+			return err
+		}
+		Level.SetLevel(lvl)
+	}
+
+	outputs := cf.Outputs
+	if len(outputs) == 0 {
+		outputs = defaultConfiguration.Outputs
+	}
+	errorOutputs := cf.ErrorOutputs
+	if len(errorOutputs) == 0 {
+		errorOutputs = defaultConfiguration.ErrorOutputs
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var opened []io.Closer
+	lowWriter, err := openSinks(outputs, cf.Rotation, &opened)
+	if err != nil {
+		closeAll(opened)
+		return err
+	}
+	highWriter, err := openSinks(errorOutputs, cf.Rotation, &opened)
+	if err != nil {
+		closeAll(opened)
+		return err
+	}
+
 	lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl < zapcore.ErrorLevel
+		return Level.Enabled(lvl) && lvl < zapcore.ErrorLevel
+	})
+	highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return Level.Enabled(lvl) && lvl >= zapcore.ErrorLevel
 	})
 
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-
-	consoleEncoder := zapcore.NewJSONEncoder(encoderConfig)
+	encoder := newEncoder(cf.Encoding)
 	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stdout), lowPriority),
+		zapcore.NewCore(encoder, lowWriter, lowPriority),
+		zapcore.NewCore(encoder, highWriter, highPriority),
 	)
 
 	logger := zap.New(core, zap.AddCaller())
-	defer logger.Sync()
-
 	zap.ReplaceGlobals(logger)
 	Log = zap.S()
+
+	// ปิดไฟล์ชุดเก่า
+	closeAll(closers)
+	closers = opened
+
+	return nil
+}
+
+// newEncoder new encoder
+func newEncoder(encoding string) zapcore.Encoder {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	if encoding == EncodingConsole {
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	}
+
+	return zapcore.NewJSONEncoder(encoderConfig)
+}
+
+// openSinks open write syncer from outputs
+func openSinks(outputs []string, rotation Rotation, opened *[]io.Closer) (zapcore.WriteSyncer, error) {
+	var writers []zapcore.WriteSyncer
+	for _, output := range outputs {
+		switch output {
+		case OutputStdout:
+			writers = append(writers, zapcore.Lock(os.Stdout))
+
+		case OutputStderr:
+			writers = append(writers, zapcore.Lock(os.Stderr))
+
+		default:
+			if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+				return nil, err
+			}
+
+			w := &lumberjack.Logger{
+				Filename:   output,
+				MaxSize:    rotation.MaxSize,
+				MaxBackups: rotation.MaxBackups,
+				MaxAge:     rotation.MaxAge,
+				Compress:   rotation.Compress,
+			}
+			*opened = append(*opened, w)
+			writers = append(writers, zapcore.AddSync(w))
+		}
+	}
+
+	return zapcore.NewMultiWriteSyncer(writers...), nil
+}
+
+// closeAll ปิดไฟล์ log ทั้งหมด
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		_ = c.Close()
+	}
+}
+
+// Sync flush log
+func Sync() error {
+	return zap.L().Sync()
 }
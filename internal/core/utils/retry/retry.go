@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	defaultBackoff    = time.Second
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// Policy retry policy
+type Policy struct {
+	Attempts   int           // จำนวนครั้งทั้งหมด (<= 1 = ไม่ retry)
+	Backoff    time.Duration // ระยะรอครั้งแรก เพิ่มเป็น 2 เท่าทุกครั้ง
+	MaxBackoff time.Duration
+}
+
+// Do run fn จนสำเร็จหรือครบจำนวนครั้ง
+func Do(ctx context.Context, p Policy, fn func(attempt int) error) error {
+	attempts := p.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = fn(attempt)
+		if err == nil || attempt == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return err
+}
@@ -11,8 +11,8 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	jwtware "github.com/saveblush/gofiber3-contrib/jwt"
 
+	"github.com/saveblush/reraw-api/internal/core/cctx"
 	"github.com/saveblush/reraw-api/internal/core/config"
-	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 	"github.com/saveblush/reraw-api/internal/models"
 )
 
@@ -26,7 +26,7 @@ func AuthorizationRequired() fiber.Handler {
 	basicAuth := basicauth.New(basicauth.Config{
 		Users: users,
 		Unauthorized: func(c fiber.Ctx) error {
-			logger.Log.Error("authorization error: unauthorized")
+			cctx.New(c).Logger().Error("authorization error: unauthorized")
 			return fiber.NewError(config.RR.Internal.Unauthorized.HTTPStatusCode(), config.RR.InvalidToken.WithLocale(c).Error())
 		},
 	})
@@ -60,7 +60,7 @@ func AuthorizationAdminRequired() fiber.Handler {
 		basicAuth := basicauth.New(basicauth.Config{
 			Users: users,
 			Unauthorized: func(c fiber.Ctx) error {
-				logger.Log.Error("authorization admin error: unauthorized")
+				cctx.New(c).Logger().Error("authorization admin error: unauthorized")
 				return fiber.ErrUnauthorized
 			},
 		})
@@ -81,7 +81,7 @@ func AuthorizationAPIKey() fiber.Handler {
 				return c.Next()
 			},
 			ErrorHandler: func(c fiber.Ctx, err error) error {
-				logger.Log.Error("authorization x-api-key error: unauthorized")
+				cctx.New(c).Logger().Error("authorization x-api-key error: unauthorized")
 				if err == keyauth.ErrMissingOrMalformedAPIKey {
 					return fiber.NewError(config.RR.Internal.Unauthorized.HTTPStatusCode(), config.RR.InvalidToken.WithLocale(c).Error())
 				}
@@ -5,8 +5,8 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 
+	"github.com/saveblush/reraw-api/internal/core/cctx"
 	"github.com/saveblush/reraw-api/internal/core/config"
-	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 	"github.com/saveblush/reraw-api/internal/handlers/render"
 )
 
@@ -29,7 +29,7 @@ func Maintenance() fiber.Handler {
 		path := fmt.Sprintf("./templates/%s", config.CF.HTMLTemplate.SystemMaintenance)
 		body, err := config.CF.ReadConfigAvailableDescription()
 		if err != nil {
-			logger.Log.Error("read file config available description error:", err)
+			cctx.New(c).Logger().Error("read file config available description error:", err)
 			return fiber.NewError(fiber.StatusServiceUnavailable, "Error: Available Description")
 		}
 
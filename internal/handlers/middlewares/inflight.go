@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var inFlight atomic.Int64
+
+// InFlight นับ request ที่กำลังทำงานอยู่
+func InFlight() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		return c.Next()
+	}
+}
+
+// InFlightRequests จำนวน request ที่กำลังทำงานอยู่
+func InFlightRequests() int64 {
+	return inFlight.Load()
+}
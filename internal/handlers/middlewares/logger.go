@@ -1,8 +1,8 @@
 package middlewares
 
 import (
-	"bytes"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -15,49 +15,255 @@ import (
 
 	"github.com/saveblush/reraw-api/internal/core/cctx"
 	"github.com/saveblush/reraw-api/internal/core/config"
-	"github.com/saveblush/reraw-api/internal/core/utils"
-	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+)
+
+var (
+	redactedValue = "**********"
+	truncatedMark = "...(truncated)"
+
+	// defaultMaxBodySize ขนาด body สูงสุดที่เก็บใน log เมื่อไม่กำหนด MAX_BODY_SIZE
+	defaultMaxBodySize = 4 * 1024
 )
 
 // Logger logger
+// access log ตาม config ACCESS_LOG
 func Logger() fiber.Handler {
 	return func(c fiber.Ctx) error {
-		start := utils.Now()
+		start := time.Now()
 		err := c.Next()
-		if err != nil {
+
+		cf := &config.CF.AccessLog
+		if !cf.Enable || !accessLogAllowed(cf.IncludePaths, cf.ExcludePaths, c.Path()) {
 			return err
 		}
 
-		var b []byte
-		parameters := c.Locals(cctx.ParametersKey)
-		if parameters != nil {
-			b, _ = json.Marshal(&parameters)
-			for _, f := range []string{"Password", "password"} {
-				if res := gjson.GetBytes(b, f); res.Exists() {
-					b, _ = sjson.SetBytes(b, f, "**********")
-				}
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
 			}
 		}
 
-		logs := logger.Log.With(
+		var parameters []byte
+		if v := c.Locals(cctx.ParametersKey); v != nil {
+			parameters, _ = json.Marshal(&v)
+			parameters = redactJSON(parameters, cf.RedactFields)
+		}
+
+		path := redactURL(c.OriginalURL(), cf.RedactFields)
+		fields := []interface{}{
 			zap.String("host", c.Hostname()),
 			zap.String("method", c.Method()),
-			zap.String("path", c.OriginalURL()),
+			zap.String("path", path),
+			zap.Int("status", status),
 			zap.Any("language", c.Locals(cctx.LangKey)),
 			zap.String("ip", c.IP()),
 			zap.Any("ips", c.IPs()),
 			zap.String("user_agent", c.Get(fiber.HeaderUserAgent)),
-			zap.String("body_size", fmt.Sprintf("%.5f MB", float64(bytes.NewReader(c.Request().Body()).Len())/1024/1024)),
-			zap.Any("process_time", time.Since(start)),
-			zap.String("parameters", string(b)),
-		)
-
-		if c.OriginalURL() != fmt.Sprintf("%s/healthcheck", config.CF.App.ApiBaseUrl) {
-			if !strings.HasPrefix(c.OriginalURL(), fmt.Sprintf("%s/swagger", config.CF.Swagger.BaseURL)) {
-				logs.Infof("[%s][%s] response: %v", c.Method(), c.OriginalURL(), string(c.Response().Body()))
+			zap.Int("body_size", len(c.Request().Body())),
+			zap.Duration("process_time", time.Since(start)),
+			zap.String("parameters", string(parameters)),
+		}
+
+		if cf.Headers {
+			fields = append(fields, zap.Any("headers", redactHeaders(c.GetReqHeaders(), cf.RedactHeaders)))
+		}
+		if cf.RequestBody {
+			b := redactBody(c.Request().Body(), cf.RedactFields)
+			fields = append(fields, zap.String("request_body", truncateBody(b, cf.MaxBodySize)))
+		}
+		if cf.ResponseBody {
+			b := redactBody(c.Response().Body(), cf.RedactFields)
+			fields = append(fields, zap.String("response_body", truncateBody(b, cf.MaxBodySize)))
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+
+		logs := cctx.New(c).Logger().With(fields...)
+		msg := fmt.Sprintf("[%s][%s] %d", c.Method(), path, status)
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			logs.Error(msg)
+		case status >= fiber.StatusBadRequest:
+			logs.Warn(msg)
+		default:
+			logs.Info(msg)
+		}
+
+		return err
+	}
+}
+
+// accessLogAllowed check path ที่ต้องเก็บ log
+// ถ้ากำหนด include จะเก็บเฉพาะ path ที่ขึ้นต้นตาม include
+func accessLogAllowed(include, exclude []string, path string) bool {
+	for _, p := range exclude {
+		if strings.HasPrefix(path, p) {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+
+	for _, p := range include {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// truncateBody truncate body by max size
+// max size 0 ใช้ค่า default 4KiB, < 0 ไม่ตัด
+func truncateBody(b []byte, max int) string {
+	if max == 0 {
+		max = defaultMaxBodySize
+	}
+	if max > 0 && len(b) > max {
+		return string(b[:max]) + truncatedMark
+	}
+
+	return string(b)
+}
+
+// redactHeaders redact header
+func redactHeaders(headers map[string][]string, names []string) map[string][]string {
+	res := make(map[string][]string, len(headers))
+	for k, v := range headers {
+		res[k] = v
+		for _, name := range names {
+			if strings.EqualFold(k, name) {
+				res[k] = []string{redactedValue}
+				break
 			}
 		}
+	}
+
+	return res
+}
 
-		return nil
+// redactBody redact body ที่เป็น json
+// body อื่นถือเป็น form urlencoded (key=value&...) ไม่ขึ้นกับ content type ที่ client ส่งมา
+func redactBody(b []byte, fields []string) []byte {
+	if len(b) == 0 || len(fields) == 0 {
+		return b
 	}
+	if gjson.ValidBytes(b) {
+		return redactJSON(b, fields)
+	}
+
+	return []byte(redactQuery(string(b), fields))
+}
+
+// redactURL redact query string ของ url
+func redactURL(u string, fields []string) string {
+	path, query, found := strings.Cut(u, "?")
+	if !found {
+		return u
+	}
+
+	return path + "?" + redactQuery(query, fields)
+}
+
+// redactQuery redact ค่าใน query string/form ที่ชื่อ key ตรงกับ field (ไม่สนตัวพิมพ์)
+// คงลำดับและ encoding เดิมของ key
+func redactQuery(q string, fields []string) string {
+	if q == "" || len(fields) == 0 {
+		return q
+	}
+
+	pairs := strings.Split(q, "&")
+	for i, pair := range pairs {
+		k, _, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			key = k
+		}
+
+		for _, f := range fields {
+			if strings.EqualFold(key, f) {
+				pairs[i] = k + "=" + redactedValue
+				break
+			}
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// redactJSON redact field ใน json
+// field ที่มี "." เป็น path เต็ม เช่น user.password
+// field ที่ไม่มี "." จะ redact ทุก key ที่ชื่อตรงกันทุกระดับ
+func redactJSON(b []byte, fields []string) []byte {
+	if len(b) == 0 || len(fields) == 0 || !gjson.ValidBytes(b) {
+		return b
+	}
+
+	var paths []string
+	var names []string
+	for _, f := range fields {
+		if strings.Contains(f, ".") {
+			paths = append(paths, f)
+		} else {
+			names = append(names, f)
+		}
+	}
+
+	if len(names) > 0 {
+		paths = append(paths, findKeyPaths(gjson.ParseBytes(b), "", names)...)
+	}
+
+	for _, p := range paths {
+		if gjson.GetBytes(b, p).Exists() {
+			b, _ = sjson.SetBytes(b, p, redactedValue)
+		}
+	}
+
+	return b
+}
+
+// findKeyPaths find path ของ key ที่ชื่อตรงกันทุกระดับ
+func findKeyPaths(v gjson.Result, prefix string, names []string) []string {
+	var paths []string
+	if !v.IsObject() && !v.IsArray() {
+		return paths
+	}
+
+	i := 0
+	v.ForEach(func(key, value gjson.Result) bool {
+		k := key.String()
+		if v.IsArray() {
+			k = fmt.Sprintf("%d", i)
+			i++
+		}
+		path := escapePath(k)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		if v.IsObject() {
+			for _, name := range names {
+				if strings.EqualFold(k, name) {
+					paths = append(paths, path)
+					return true
+				}
+			}
+		}
+
+		paths = append(paths, findKeyPaths(value, path, names)...)
+		return true
+	})
+
+	return paths
+}
+
+// escapePath escape อักขระพิเศษของ gjson path
+func escapePath(k string) string {
+	r := strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`)
+	return r.Replace(k)
 }
@@ -0,0 +1,140 @@
+package middlewares
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+		want   string
+	}{
+		{
+			name:   "no fields",
+			body:   `{"password":"secret"}`,
+			fields: nil,
+			want:   `{"password":"secret"}`,
+		},
+		{
+			name:   "empty body",
+			body:   ``,
+			fields: []string{"password"},
+			want:   ``,
+		},
+		{
+			name:   "invalid json",
+			body:   `password=secret`,
+			fields: []string{"password"},
+			want:   `password=**********`,
+		},
+		{
+			name:   "form urlencoded",
+			body:   `name=alice&Password=secret&user%2Etoken=a&token`,
+			fields: []string{"password", "user.token", "token"},
+			want:   `name=alice&Password=**********&user%2Etoken=**********&token=**********`,
+		},
+		{
+			name:   "top level key",
+			body:   `{"name":"alice","password":"secret"}`,
+			fields: []string{"password"},
+			want:   `{"name":"alice","password":"**********"}`,
+		},
+		{
+			name:   "key at any depth ignoring case",
+			body:   `{"user":{"Password":"secret","profile":{"password":1}}}`,
+			fields: []string{"password"},
+			want:   `{"user":{"Password":"**********","profile":{"password":"**********"}}}`,
+		},
+		{
+			name:   "key inside array",
+			body:   `{"users":[{"token":"a"},{"token":"b"}]}`,
+			fields: []string{"token"},
+			want:   `{"users":[{"token":"**********"},{"token":"**********"}]}`,
+		},
+		{
+			name:   "object value",
+			body:   `{"secret":{"a":1},"b":2}`,
+			fields: []string{"secret"},
+			want:   `{"secret":"**********","b":2}`,
+		},
+		{
+			name:   "full path only",
+			body:   `{"user":{"token":"a"},"token":"b"}`,
+			fields: []string{"user.token"},
+			want:   `{"user":{"token":"**********"},"token":"b"}`,
+		},
+		{
+			name:   "key with special characters",
+			body:   `{"a.b":{"password":"secret"}}`,
+			fields: []string{"password"},
+			want:   `{"a.b":{"password":"**********"}}`,
+		},
+		{
+			name:   "missing field",
+			body:   `{"name":"alice"}`,
+			fields: []string{"password", "user.token"},
+			want:   `{"name":"alice"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactBody([]byte(tt.body), tt.fields)
+			if string(got) != tt.want {
+				t.Fatalf("redactBody(%s, %v) = %s, want %s", tt.body, tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		fields []string
+		want   string
+	}{
+		{name: "no query", url: "/api/v1/users", fields: []string{"token"}, want: "/api/v1/users"},
+		{name: "no fields", url: "/a?token=x", fields: nil, want: "/a?token=x"},
+		{name: "query", url: "/a?name=alice&TOKEN=x&token=y", fields: []string{"token"}, want: "/a?name=alice&TOKEN=**********&token=**********"},
+		{name: "empty value", url: "/a?token=", fields: []string{"token"}, want: "/a?token=**********"},
+		{name: "missing field", url: "/a?name=alice", fields: []string{"token"}, want: "/a?name=alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactURL(tt.url, tt.fields); got != tt.want {
+				t.Fatalf("redactURL(%q, %v) = %q, want %q", tt.url, tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		max  int
+		want string
+	}{
+		{name: "shorter than max", body: "abc", max: 4, want: "abc"},
+		{name: "equal to max", body: "abcd", max: 4, want: "abcd"},
+		{name: "longer than max", body: "abcdef", max: 4, want: "abcd" + truncatedMark},
+		{name: "empty", body: "", max: 4, want: ""},
+		{name: "default", body: strings.Repeat("a", defaultMaxBodySize+1), max: 0, want: strings.Repeat("a", defaultMaxBodySize) + truncatedMark},
+		{name: "default not reached", body: strings.Repeat("a", defaultMaxBodySize), max: 0, want: strings.Repeat("a", defaultMaxBodySize)},
+		{name: "unlimited", body: strings.Repeat("a", defaultMaxBodySize+1), max: -1, want: strings.Repeat("a", defaultMaxBodySize+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateBody([]byte(tt.body), tt.max)
+			if got != tt.want {
+				t.Fatalf("truncateBody(%q, %d) = %q, want %q", tt.body, tt.max, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/saveblush/reraw-api/internal/core/metrics"
+)
+
+// Metrics metrics http request
+// นับจำนวน request และ latency แยกตาม route
+func Metrics() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		route := c.Route().Path
+		method := c.Method()
+		metrics.HTTPRequestsTotal.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/saveblush/reraw-api/internal/core/tracing"
+)
+
+// Tracing tracing http request
+// สร้าง span ต่อ request และเก็บไว้ใน context
+func Tracing() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		carrier := propagation.MapCarrier{}
+		c.Request().Header.VisitAll(func(k, v []byte) {
+			carrier.Set(string(k), string(v))
+		})
+		ctx := otel.GetTextMapPropagator().Extract(c.Context(), carrier)
+
+		ctx, span := tracing.Tracer().Start(ctx, fmt.Sprintf("%s %s", c.Method(), c.Path()),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+				attribute.String("server.address", c.Hostname()),
+				attribute.String("client.address", c.IP()),
+				attribute.String("user_agent.original", c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		c.SetContext(ctx)
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if e, ok := err.(*fiber.Error); ok {
+			status = e.Code
+		}
+
+		span.SetName(fmt.Sprintf("%s %s", c.Method(), c.Route().Path))
+		span.SetAttributes(
+			attribute.String("http.route", c.Route().Path),
+			attribute.Int("http.response.status_code", status),
+		)
+		if err != nil {
+			span.RecordError(err)
+		}
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("status %d", status))
+		}
+
+		return err
+	}
+}
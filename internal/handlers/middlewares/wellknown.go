@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/saveblush/reraw-api/internal/core/config"
+)
+
+var (
+	// headerDegraded header ที่ service ตั้งเมื่อตอบจากแหล่งสำรอง
+	headerDegraded = "X-Degraded"
+)
+
+// WellKnown header สำหรับ route /.well-known/nostr.json
+// ETag, conditional GET, Cache-Control และ CORS ตาม NIP-05
+func WellKnown() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		// NIP-05 กำหนดให้ต้องเปิด CORS ทุก origin
+		c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+
+		err := c.Next()
+		if err != nil || c.Response().StatusCode() != fiber.StatusOK {
+			return err
+		}
+
+		// ข้อมูลจากแหล่งสำรอง (รวม document ที่ไม่มี name) ห้ามให้ client หรือ cdn เก็บไว้
+		if c.GetRespHeader(headerDegraded) != "" {
+			c.Set(fiber.HeaderCacheControl, "no-store")
+			return nil
+		}
+
+		cf := config.CF.WellKnown.CacheControl
+		c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d",
+			int(cf.MaxAge.Seconds()),
+			int(cf.StaleWhileRevalidate.Seconds()),
+		))
+
+		etag := computeETag(c.Response().Body())
+		c.Set(fiber.HeaderETag, etag)
+
+		if etagMatch(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			c.Response().ResetBody()
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		return nil
+	}
+}
+
+// computeETag compute strong etag จาก body
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+}
+
+// etagMatch check If-None-Match
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		v = strings.TrimPrefix(v, "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+
+	return false
+}
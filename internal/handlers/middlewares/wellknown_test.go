@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/saveblush/reraw-api/internal/core/config"
+)
+
+func TestWellKnown(t *testing.T) {
+	cf := &config.Configs{}
+	cf.WellKnown.CacheControl.MaxAge = time.Minute
+	cf.WellKnown.CacheControl.StaleWhileRevalidate = time.Hour
+	config.CF = cf
+
+	body := `{"names":{"alice":"b0635d6a"}}`
+	etag := computeETag([]byte(body))
+
+	tests := []struct {
+		name        string
+		degraded    string
+		ifNoneMatch string
+		wantStatus  int
+		wantCache   string
+		wantETag    string
+	}{
+		{
+			name:       "fresh",
+			wantStatus: fiber.StatusOK,
+			wantCache:  "public, max-age=60, stale-while-revalidate=3600",
+			wantETag:   etag,
+		},
+		{
+			name:        "not modified",
+			ifNoneMatch: `W/` + etag,
+			wantStatus:  fiber.StatusNotModified,
+			wantCache:   "public, max-age=60, stale-while-revalidate=3600",
+			wantETag:    etag,
+		},
+		{
+			name:       "degraded",
+			degraded:   "stale-cache",
+			wantStatus: fiber.StatusOK,
+			wantCache:  "no-store",
+		},
+		{
+			name:        "degraded ignores if-none-match",
+			degraded:    "unavailable",
+			ifNoneMatch: etag,
+			wantStatus:  fiber.StatusOK,
+			wantCache:   "no-store",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/.well-known/nostr.json", func(c fiber.Ctx) error {
+				if tt.degraded != "" {
+					c.Set(headerDegraded, tt.degraded)
+				}
+				return c.SendString(body)
+			}, WellKnown())
+
+			req := httptest.NewRequest(fiber.MethodGet, "/.well-known/nostr.json", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(fiber.HeaderIfNoneMatch, tt.ifNoneMatch)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %s", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(fiber.HeaderCacheControl); got != tt.wantCache {
+				t.Fatalf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+			if got := resp.Header.Get(fiber.HeaderETag); got != tt.wantETag {
+				t.Fatalf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "*" {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want *", got)
+			}
+		})
+	}
+}
@@ -13,6 +13,13 @@ func JSON(c fiber.Ctx, response interface{}) error {
 		JSON(response)
 }
 
+// JSONStatus render json with status to client
+func JSONStatus(c fiber.Ctx, status int, response interface{}) error {
+	return c.
+		Status(status).
+		JSON(response)
+}
+
 // Byte render byte to client
 func Byte(c fiber.Ctx, bytes []byte) error {
 	_, err := c.Status(fiber.StatusOK).
@@ -6,7 +6,6 @@ import (
 	"github.com/gofiber/fiber/v3"
 
 	"github.com/saveblush/reraw-api/internal/core/cctx"
-	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 	"github.com/saveblush/reraw-api/internal/handlers/render"
 	"github.com/saveblush/reraw-api/internal/models"
 )
@@ -16,7 +15,7 @@ func ResponseObject(c fiber.Ctx, fn interface{}, request interface{}) error {
 	ctx := cctx.New(c)
 	err := ctx.BindValue(request, true)
 	if err != nil {
-		logger.Log.Errorf("bind value error: %s", err)
+		ctx.Logger().Errorf("bind value error: %s", err)
 		return err
 	}
 
@@ -27,7 +26,7 @@ func ResponseObject(c fiber.Ctx, fn interface{}, request interface{}) error {
 
 	errObj := out[1].Interface()
 	if errObj != nil {
-		logger.Log.Errorf("call service error: %s", errObj)
+		ctx.Logger().Errorf("call service error: %s", errObj)
 		return errObj.(error)
 	}
 
@@ -43,7 +42,7 @@ func ResponseObjectWithoutRequest(c fiber.Ctx, fn interface{}) error {
 
 	errObj := out[1].Interface()
 	if errObj != nil {
-		logger.Log.Errorf("call service error: %s", errObj)
+		ctx.Logger().Errorf("call service error: %s", errObj)
 		return errObj.(error)
 	}
 
@@ -55,7 +54,7 @@ func ResponseSuccess(c fiber.Ctx, fn interface{}, request interface{}) error {
 	ctx := cctx.New(c)
 	err := ctx.BindValue(request, true)
 	if err != nil {
-		logger.Log.Errorf("bind value error: %s", err)
+		ctx.Logger().Errorf("bind value error: %s", err)
 		return err
 	}
 
@@ -66,7 +65,7 @@ func ResponseSuccess(c fiber.Ctx, fn interface{}, request interface{}) error {
 
 	errObj := out[0].Interface()
 	if errObj != nil {
-		logger.Log.Errorf("call service error: %s", errObj)
+		ctx.Logger().Errorf("call service error: %s", errObj)
 		return errObj.(error)
 	}
 
@@ -82,7 +81,7 @@ func ResponseSuccessWithoutRequest(c fiber.Ctx, fn interface{}) error {
 
 	errObj := out[0].Interface()
 	if errObj != nil {
-		logger.Log.Errorf("call service error: %s", errObj)
+		ctx.Logger().Errorf("call service error: %s", errObj)
 		return errObj.(error)
 	}
 
@@ -1,9 +1,12 @@
 package routes
 
 import (
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
 	swagger "github.com/saveblush/gofiber3-swagger"
 
 	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/metrics"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 	"github.com/saveblush/reraw-api/internal/handlers/middlewares"
 	"github.com/saveblush/reraw-api/internal/pgk/healthcheck"
 	"github.com/saveblush/reraw-api/internal/pgk/system"
@@ -15,19 +18,32 @@ func (s *server) InitRouter() {
 	// api
 	api := s.Group(config.CF.App.ApiBaseUrl)
 
+	// user nostr
+	userEndpoint := user.NewEndpoint(s.cache)
+
 	// system
 	systemEndpoint := system.NewEndpoint()
 	systemRoute := api.Group("/system")
 	systemRoute.Post("/action", systemEndpoint.Action, middlewares.AuthorizationAdminRequired())
 	systemRoute.Get("/maintenance", middlewares.Maintenance())
 
+	// log level (GET ดูค่า, PUT {"level":"debug"} เปลี่ยนค่า)
+	logLevelHandler := adaptor.HTTPHandler(logger.Level)
+	systemRoute.Get("/log-level", logLevelHandler, middlewares.AuthorizationAdminRequired())
+	systemRoute.Put("/log-level", logLevelHandler, middlewares.AuthorizationAdminRequired())
+	systemRoute.Post("/export-nostr", userEndpoint.ExportWellKnown, middlewares.AuthorizationAdminRequired())
+
+	// background jobs
+	systemRoute.Get("/jobs", systemEndpoint.Jobs, middlewares.AuthorizationAdminRequired())
+	systemRoute.Post("/jobs/:name/run", systemEndpoint.RunJob, middlewares.AuthorizationAdminRequired())
+
 	api.Use(
 		middlewares.Available(), // ปิด/เปิด ระบบ
 		middlewares.AcceptLanguage(),
 	)
 
 	// healthcheck endpoint
-	healthCheckEndpoint := healthcheck.NewEndpoint()
+	healthCheckEndpoint := healthcheck.NewEndpoint(s.cache)
 	api.Get("/healthcheck", healthCheckEndpoint.HealthCheck)
 
 	// api v1
@@ -40,10 +56,19 @@ func (s *server) InitRouter() {
 
 	v1.Get("/healthcheck", healthCheckEndpoint.HealthCheck)
 
+	// liveness / readiness
+	s.Get("/livez", healthCheckEndpoint.Livez)
+	s.Get("/readyz", healthCheckEndpoint.Readyz)
+
+	// metrics
+	// กรณีไม่ได้แยก port จะต้อง auth admin
+	if config.CF.Metrics.Enable && config.CF.Metrics.Port == 0 {
+		s.Get(config.CF.Metrics.Path, adaptor.HTTPHandler(metrics.Handler()), middlewares.AuthorizationAdminRequired())
+	}
+
 	// user nostr
-	userEndpoint := user.NewEndpoint()
 	userRoute := s
-	userRoute.Get(".well-known/nostr.json", userEndpoint.FindWellKnownName)
+	userRoute.Get(".well-known/nostr.json", userEndpoint.FindWellKnownName, middlewares.WellKnown())
 	userRoute.Get(".well-known/lnurlp/:name", userEndpoint.FindWellKnownLNURL)
 
 	// not found
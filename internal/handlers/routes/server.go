@@ -15,6 +15,7 @@ import (
 
 	"github.com/saveblush/reraw-api/internal/core/cctx"
 	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
 	"github.com/saveblush/reraw-api/internal/handlers/middlewares"
 )
 
@@ -29,6 +30,13 @@ const (
 	Timeout10s = 10 * time.Second
 )
 
+// Server server interface
+type Server interface {
+	InitRouter()
+	Listen(addr string, config ...fiber.ListenConfig) error
+	Close(timeout time.Duration) error
+}
+
 type server struct {
 	// fiber
 	*fiber.App
@@ -38,10 +46,13 @@ type server struct {
 
 	// config
 	config *config.Configs
+
+	// cache
+	cache cache.Service
 }
 
 // NewServer new server
-func NewServer() (*server, error) {
+func NewServer(cache cache.Service) (*server, error) {
 	// New fiber app
 	app := fiber.New(fiber.Config{
 		AppName:           config.CF.App.ProjectName,
@@ -58,6 +69,7 @@ func NewServer() (*server, error) {
 
 	// Middlewares
 	app.Use(
+		middlewares.InFlight(),
 		compress.New(compress.Config{
 			Level: compress.LevelBestCompression,
 		}),
@@ -76,6 +88,16 @@ func NewServer() (*server, error) {
 		}))
 	}
 
+	// Tracing
+	if config.CF.Tracing.Enable {
+		app.Use(middlewares.Tracing())
+	}
+
+	// Metrics
+	if config.CF.Metrics.Enable {
+		app.Use(middlewares.Metrics())
+	}
+
 	// Middlewares custom
 	app.Use(
 		middlewares.Logger(),
@@ -86,13 +108,15 @@ func NewServer() (*server, error) {
 		App:    app,
 		cctx:   &cctx.Context{},
 		config: config.CF,
+		cache:  cache,
 	}, nil
 }
 
 // Close close server
-func (s *server) Close() error {
+// หยุดรับ connection ใหม่ และรอ request ที่ค้างอยู่ไม่เกิน timeout
+func (s *server) Close(timeout time.Duration) error {
 	// Shutdown server
-	err := s.Shutdown()
+	err := s.ShutdownWithTimeout(timeout)
 	if err != nil {
 		return err
 	}
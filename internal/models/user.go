@@ -1,5 +1,12 @@
 package models
 
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/goccy/go-json"
+)
+
 type Timestamp int64
 
 type User struct {
@@ -9,8 +16,49 @@ type User struct {
 	DeletedAt    Timestamp `json:"deleted_at" gorm:"type:integer"`
 	Name         string    `json:"name"`
 	LightningURL string    `json:"lightning_url"`
+	Relays       Relays    `json:"relays,omitempty" gorm:"type:text"`
+	Domain       string    `json:"domain,omitempty" gorm:"type:varchar(255)"` // ว่าง = ทุก domain
 }
 
 func (User) TableName() string {
 	return "users"
 }
+
+// Relays relay list ของ user เก็บเป็น json array
+// ว่าง = ใช้ relay list กลาง (NULL ใน database)
+type Relays []string
+
+// Value implements driver.Valuer
+func (r Relays) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return nil, nil
+	}
+
+	b, err := json.Marshal([]string(r))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (r *Relays) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("relays: unsupported type %T", value)
+	}
+
+	if len(b) == 0 {
+		*r = nil
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(r))
+}
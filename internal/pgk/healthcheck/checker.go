@@ -0,0 +1,69 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+)
+
+// Checker checker dependency
+type Checker struct {
+	Name     string
+	Critical bool // false = แสดงผลอย่างเดียว ไม่ทำให้ readiness fail
+	Check    func(ctx context.Context) error
+}
+
+// NewDatabaseChecker new checker database
+func NewDatabaseChecker() *Checker {
+	return &Checker{
+		Name:     "database",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return sql.Ping(ctx, sql.Database)
+		},
+	}
+}
+
+// NewCacheChecker new checker cache
+func NewCacheChecker(cache cache.Service) *Checker {
+	return &Checker{
+		Name:     "cache",
+		Critical: true,
+		Check:    cache.Ping,
+	}
+}
+
+// NewRelayChecker new checker relay
+// เช็คว่าเชื่อมต่อ relay ได้ (tcp)
+func NewRelayChecker(relay string) *Checker {
+	return &Checker{
+		Name:     fmt.Sprintf("relay:%s", relay),
+		Critical: false,
+		Check: func(ctx context.Context) error {
+			u, err := url.Parse(relay)
+			if err != nil {
+				return err
+			}
+
+			port := u.Port()
+			if port == "" {
+				port = "443"
+				if u.Scheme == "ws" {
+					port = "80"
+				}
+			}
+
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+			if err != nil {
+				return err
+			}
+
+			return conn.Close()
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 
 	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
 	"github.com/saveblush/reraw-api/internal/handlers/render"
 	"github.com/saveblush/reraw-api/internal/models"
 )
@@ -11,17 +12,31 @@ import (
 // endpoint interface
 type Endpoint interface {
 	HealthCheck(c fiber.Ctx) error
+	Livez(c fiber.Ctx) error
+	Readyz(c fiber.Ctx) error
 }
 
 type endpoint struct {
-	config *config.Configs
-	result *config.ReturnResult
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
 }
 
-func NewEndpoint() Endpoint {
+func NewEndpoint(cache cache.Service) Endpoint {
+	checkers := []*Checker{
+		NewDatabaseChecker(),
+		NewCacheChecker(cache),
+	}
+	if config.CF.HealthCheck.Relays {
+		for _, relay := range config.CF.App.LazyRelays {
+			checkers = append(checkers, NewRelayChecker(relay))
+		}
+	}
+
 	return &endpoint{
-		config: config.CF,
-		result: config.RR,
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(checkers...),
 	}
 }
 
@@ -29,3 +44,20 @@ func NewEndpoint() Endpoint {
 func (ep *endpoint) HealthCheck(c fiber.Ctx) error {
 	return render.JSON(c, models.NewSuccessMessage())
 }
+
+// Livez liveness
+// process ยังทำงานอยู่
+func (ep *endpoint) Livez(c fiber.Ctx) error {
+	return render.JSON(c, models.NewSuccessMessage())
+}
+
+// Readyz readiness
+// เช็ค dependency ทั้งหมด ถ้าไม่พร้อมตอบ 503
+func (ep *endpoint) Readyz(c fiber.Ctx) error {
+	res := ep.service.Readiness(c.Context())
+	if !res.OK() {
+		return render.JSONStatus(c, fiber.StatusServiceUnavailable, res)
+	}
+
+	return render.JSON(c, res)
+}
@@ -0,0 +1,140 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/lifecycle"
+)
+
+var (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+
+	defaultTimeout  = 2 * time.Second
+	defaultCacheTTL = 2 * time.Second
+)
+
+// Component status of component
+type Component struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Latency  string `json:"latency"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Readiness readiness result
+type Readiness struct {
+	Status     string                `json:"status"`
+	Components map[string]*Component `json:"components"`
+}
+
+// OK is ready
+func (r *Readiness) OK() bool {
+	return r.Status == StatusOK
+}
+
+// service interface
+type Service interface {
+	Readiness(ctx context.Context) *Readiness
+}
+
+type service struct {
+	config   *config.Configs
+	checkers []*Checker
+
+	mu        sync.Mutex
+	result    *Readiness
+	checkedAt time.Time
+}
+
+func NewService(checkers ...*Checker) Service {
+	return &service{
+		config:   config.CF,
+		checkers: checkers,
+	}
+}
+
+// Readiness check all dependencies
+// cache ผลไว้ช่วงสั้นๆ กัน ping ถี่เกินไป
+func (s *service) Readiness(ctx context.Context) *Readiness {
+	// กำลัง shutdown ไม่รับ traffic ใหม่
+	if lifecycle.Stopping() {
+		return &Readiness{
+			Status: StatusFail,
+			Components: map[string]*Component{
+				"shutdown": {
+					Status:   StatusFail,
+					Critical: true,
+					Error:    "server is shutting down",
+				},
+			},
+		}
+	}
+
+	ttl := s.config.HealthCheck.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.result != nil && time.Since(s.checkedAt) < ttl {
+		return s.result
+	}
+
+	s.result = s.check(ctx)
+	s.checkedAt = time.Now()
+
+	return s.result
+}
+
+// check run checkers concurrently
+func (s *service) check(ctx context.Context) *Readiness {
+	timeout := s.config.HealthCheck.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	res := &Readiness{
+		Status:     StatusOK,
+		Components: make(map[string]*Component, len(s.checkers)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range s.checkers {
+		wg.Add(1)
+		go func(checker *Checker) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checker.Check(ctx)
+			component := &Component{
+				Status:   StatusOK,
+				Critical: checker.Critical,
+				Latency:  time.Since(start).String(),
+			}
+			if err != nil {
+				component.Status = StatusFail
+				component.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.Components[checker.Name] = component
+			if err != nil && checker.Critical {
+				res.Status = StatusFail
+			}
+		}(checker)
+	}
+	wg.Wait()
+
+	return res
+}
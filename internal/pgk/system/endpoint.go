@@ -10,6 +10,8 @@ import (
 // endpoint interface
 type Endpoint interface {
 	Action(c fiber.Ctx) error
+	Jobs(c fiber.Ctx) error
+	RunJob(c fiber.Ctx) error
 }
 
 type endpoint struct {
@@ -29,3 +31,11 @@ func NewEndpoint() Endpoint {
 func (ep *endpoint) Action(c fiber.Ctx) error {
 	return handlers.ResponseObject(c, ep.service.Action, &Request{})
 }
+
+func (ep *endpoint) Jobs(c fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.Jobs)
+}
+
+func (ep *endpoint) RunJob(c fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.RunJob, &RequestJob{})
+}
@@ -4,3 +4,7 @@ type Request struct {
 	Status string `json:"status" validate:"required"`
 	Body   string `json:"body" validate:"required"`
 }
+
+type RequestJob struct {
+	Name string `json:"name" path:"name" validate:"required"`
+}
@@ -1,13 +1,21 @@
 package system
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+
 	"github.com/saveblush/reraw-api/internal/core/cctx"
 	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/jobs"
 )
 
 // service interface
 type Service interface {
 	Action(c *cctx.Context, req *Request) (interface{}, error)
+	Jobs(c *cctx.Context) (interface{}, error)
+	RunJob(c *cctx.Context, req *RequestJob) error
 }
 
 type service struct {
@@ -43,3 +51,27 @@ func (s *service) Action(c *cctx.Context, req *Request) (interface{}, error) {
 		"system": status,
 	}, nil
 }
+
+// Jobs สถานะของ background job ทั้งหมด
+func (s *service) Jobs(c *cctx.Context) (interface{}, error) {
+	res, err := jobs.List(c.Context())
+	if err != nil {
+		c.Logger().Errorf("list jobs error: %s", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RunJob สั่งทำ job ทันที
+func (s *service) RunJob(c *cctx.Context, req *RequestJob) error {
+	err := jobs.Trigger(req.Name)
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return s.result.CustomMessage(err.Error(), fmt.Sprintf("ไม่พบ job %s", req.Name), fiber.StatusNotFound)
+	case errors.Is(err, jobs.ErrRunning):
+		return s.result.CustomMessage(err.Error(), fmt.Sprintf("job %s กำลังทำงานอยู่", req.Name), fiber.StatusBadRequest)
+	}
+
+	return err
+}
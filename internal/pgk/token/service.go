@@ -8,7 +8,6 @@ import (
 	"github.com/saveblush/reraw-api/internal/core/cctx"
 	"github.com/saveblush/reraw-api/internal/core/config"
 	"github.com/saveblush/reraw-api/internal/core/utils"
-	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 	"github.com/saveblush/reraw-api/internal/models"
 )
 
@@ -34,13 +33,13 @@ func NewService() Service {
 func (s *service) Create(c *cctx.Context, req *Request) (*models.Token, error) {
 	accessToken, err := s.genToken(req)
 	if err != nil {
-		logger.Log.Errorf("create accessToken error: %s", err)
+		c.Logger().Errorf("create accessToken error: %s", err)
 		return nil, err
 	}
 
 	refreshToken, err := s.genRefreshToken(req)
 	if err != nil {
-		logger.Log.Errorf("create refreshToken error: %s", err)
+		c.Logger().Errorf("create refreshToken error: %s", err)
 		return nil, err
 	}
 
@@ -0,0 +1,217 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+var (
+	// ErrUserNotFound ไม่พบ user
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUserExists name หรือ pubkey ถูกใช้แล้ว
+	ErrUserExists = errors.New("user already exists")
+
+	// NIP-05 local-part และ pubkey แบบ hex
+	patternName   = regexp.MustCompile(`^[a-z0-9._-]+$`)
+	patternPubkey = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+	// ชื่อ domain ใช้เป็นส่วนหนึ่งของ path ไฟล์ export
+	patternDomain = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+$`)
+)
+
+// Admin จัดการข้อมูล user (ใช้จาก command line)
+// ทุกการแก้ไขจะลบ cache ที่เกี่ยวข้องผ่าน UsersChanged
+type Admin struct {
+	db         *gorm.DB
+	cache      cache.Service
+	repository Repository
+}
+
+// NewAdmin new admin
+func NewAdmin(db *gorm.DB, cache cache.Service) *Admin {
+	return &Admin{
+		db:         db,
+		cache:      cache,
+		repository: NewRepository(),
+	}
+}
+
+// List user ทั้งหมดเรียงตามชื่อ
+func (a *Admin) List(ctx context.Context) ([]*models.User, error) {
+	var users []*models.User
+	err := a.repository.FindAllOrderByName(a.db.WithContext(ctx), &users)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Add เพิ่ม user (domain ว่าง = ทุก domain)
+func (a *Admin) Add(ctx context.Context, name, pubkey, lightningURL, domain string) (*models.User, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	pubkey = strings.ToLower(strings.TrimSpace(pubkey))
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if !patternName.MatchString(name) {
+		return nil, fmt.Errorf("invalid name %q: allowed characters are a-z 0-9 . _ -", name)
+	}
+	if !patternPubkey.MatchString(pubkey) {
+		return nil, fmt.Errorf("invalid pubkey %q: expected 64 hex characters", pubkey)
+	}
+	if err := validateLightning(lightningURL); err != nil {
+		return nil, err
+	}
+	if domain != "" && !patternDomain.MatchString(domain) {
+		return nil, fmt.Errorf("invalid domain %q", domain)
+	}
+
+	// ตรวจซ้ำที่ primary เพราะ replica อาจยังไม่เห็นข้อมูลล่าสุด
+	db := sql.Primary(a.db.WithContext(ctx))
+	for field, value := range map[string]string{"name": name, "pubkey": pubkey} {
+		_, err := a.find(db, field, value)
+		if err == nil {
+			return nil, fmt.Errorf("%w: %s %s", ErrUserExists, field, value)
+		}
+		if !errors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+	}
+
+	now := models.Timestamp(time.Now().Unix())
+	u := &models.User{
+		Pubkey:       pubkey,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Name:         name,
+		LightningURL: lightningURL,
+		Domain:       domain,
+	}
+	err := a.repository.Create(db, u)
+	if err != nil {
+		return nil, err
+	}
+	UsersChanged(ctx, a.cache, u)
+
+	return u, nil
+}
+
+// Remove ลบ user ตามชื่อ
+func (a *Admin) Remove(ctx context.Context, name string) (*models.User, error) {
+	db := sql.Primary(a.db.WithContext(ctx))
+	u, err := a.find(db, "name", strings.ToLower(name))
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.repository.Delete(db, u)
+	if err != nil {
+		return nil, err
+	}
+	UsersChanged(ctx, a.cache, u)
+
+	return u, nil
+}
+
+// SetLightning แก้ lightning address ของ user (ว่าง = ลบ)
+func (a *Admin) SetLightning(ctx context.Context, name, lightningURL string) (*models.User, error) {
+	if err := validateLightning(lightningURL); err != nil {
+		return nil, err
+	}
+
+	db := sql.Primary(a.db.WithContext(ctx))
+	u, err := a.find(db, "name", strings.ToLower(name))
+	if err != nil {
+		return nil, err
+	}
+
+	u.LightningURL = lightningURL
+	u.UpdatedAt = models.Timestamp(time.Now().Unix())
+	err = a.repository.Update(db, u, map[string]interface{}{
+		"lightning_url": u.LightningURL,
+		"updated_at":    u.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	UsersChanged(ctx, a.cache, u)
+
+	return u, nil
+}
+
+// SetRelays แก้ relay list ของ user (ว่าง = ใช้ relay list กลาง)
+func (a *Admin) SetRelays(ctx context.Context, name string, relays []string) (*models.User, error) {
+	for _, relay := range relays {
+		if err := validateRelay(relay); err != nil {
+			return nil, err
+		}
+	}
+
+	db := sql.Primary(a.db.WithContext(ctx))
+	u, err := a.find(db, "name", strings.ToLower(name))
+	if err != nil {
+		return nil, err
+	}
+
+	u.Relays = relays
+	u.UpdatedAt = models.Timestamp(time.Now().Unix())
+	err = a.repository.Update(db, u, map[string]interface{}{
+		"relays":     u.Relays,
+		"updated_at": u.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	UsersChanged(ctx, a.cache, u)
+
+	return u, nil
+}
+
+// find find user ตาม field
+func (a *Admin) find(db *gorm.DB, field, value string) (*models.User, error) {
+	u := &models.User{}
+	err := a.repository.FindByIDString(db, field, value, u)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s %s", ErrUserNotFound, field, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// validateLightning lightning address ต้องอยู่ในรูป name@domain
+func validateLightning(lightningURL string) error {
+	if lightningURL == "" {
+		return nil
+	}
+
+	name, domain, ok := strings.Cut(lightningURL, "@")
+	if !ok || name == "" || domain == "" {
+		return fmt.Errorf("invalid lightning address %q: expected name@domain", lightningURL)
+	}
+
+	return nil
+}
+
+// validateRelay relay ต้องเป็น url ws:// หรือ wss://
+func validateRelay(relay string) error {
+	u, err := url.Parse(relay)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		return fmt.Errorf("invalid relay %q: expected ws:// or wss:// url", relay)
+	}
+
+	return nil
+}
@@ -1,16 +1,22 @@
 package user
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v3"
 
+	"github.com/saveblush/reraw-api/internal/core/cctx"
 	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
 	"github.com/saveblush/reraw-api/internal/handlers"
+	"github.com/saveblush/reraw-api/internal/handlers/render"
 )
 
 // endpoint interface
 type Endpoint interface {
 	FindWellKnownName(c fiber.Ctx) error
 	FindWellKnownLNURL(c fiber.Ctx) error
+	ExportWellKnown(c fiber.Ctx) error
 }
 
 type endpoint struct {
@@ -19,11 +25,11 @@ type endpoint struct {
 	service Service
 }
 
-func NewEndpoint() Endpoint {
+func NewEndpoint(cache cache.Service) Endpoint {
 	return &endpoint{
 		config:  config.CF,
 		result:  config.RR,
-		service: NewService(),
+		service: NewService(cache),
 	}
 }
 
@@ -41,7 +47,19 @@ func NewEndpoint() Endpoint {
 // @Security ApiKeyAuth
 // @Router /.well-known/nostr.json [get]
 func (ep *endpoint) FindWellKnownName(c fiber.Ctx) error {
-	return handlers.ResponseObject(c, ep.service.FindWellKnownName, &RequestWellKnownName{})
+	// fast path ไม่ผ่าน reflection bind
+	ctx := cctx.New(c)
+	req := &RequestWellKnownName{Name: strings.TrimSpace(c.Query("name"))}
+	c.Locals(cctx.ParametersKey, req)
+
+	b, err := ep.service.FindWellKnownNameBytes(ctx, req.Name)
+	if err != nil {
+		ctx.Logger().Errorf("call service error: %s", err)
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return render.Byte(c, b)
 }
 
 // @Tags User
@@ -60,3 +78,8 @@ func (ep *endpoint) FindWellKnownName(c fiber.Ctx) error {
 func (ep *endpoint) FindWellKnownLNURL(c fiber.Ctx) error {
 	return handlers.ResponseObject(c, ep.service.FindWellKnownLNURL, &RequestWellKnownName{})
 }
+
+// ExportWellKnown export nostr.json to files
+func (ep *endpoint) ExportWellKnown(c fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.ExportWellKnown)
+}
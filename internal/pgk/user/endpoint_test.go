@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+
+	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/handlers"
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+// newTestHandler handler nostr.json บน memory cache ที่มี user alice อยู่แล้ว
+// และ bob ที่ cache ไว้ว่าไม่มีในระบบ ทั้ง 2 path อ่านจาก cache อย่างเดียว ไม่แตะ database
+func newTestHandler(tb testing.TB, fast bool) (fasthttp.RequestHandler, cache.Store) {
+	tb.Helper()
+
+	cf := &config.Configs{Validator: validator.New()}
+	cf.Cache.ExprieTime.UserInfo = time.Hour
+	cf.Cache.ExprieTime.NotFound = time.Hour
+	config.CF = cf
+
+	cacheService, err := cache.Open(&cache.Configuration{Driver: cache.DriverMemory})
+	if err != nil {
+		tb.Fatalf("open cache: %s", err)
+	}
+	tb.Cleanup(func() { _ = cacheService.Close() })
+
+	ep := NewEndpoint(cacheService).(*endpoint)
+	svc := ep.service.(*service)
+	for name, user := range map[string]*models.User{
+		"alice": {
+			Name:   "alice",
+			Pubkey: "b0635d6a9851d3aed0cd6c495b282167acf761729078d975fc341b22650b07b9",
+		},
+		"bob": {},
+	} {
+		err = cache.Set(context.Background(), cacheService.Store(), svc.setKeyUser(name), user, time.Hour)
+		if err != nil {
+			tb.Fatalf("set user cache: %s", err)
+		}
+	}
+
+	app := fiber.New()
+	if fast {
+		app.Get("/.well-known/nostr.json", ep.FindWellKnownName)
+	} else {
+		// path เดิม bind ด้วย reflection แล้ว encode document ทุก request
+		app.Get("/.well-known/nostr.json", func(c fiber.Ctx) error {
+			return handlers.ResponseObject(c, ep.service.FindWellKnownName, &RequestWellKnownName{})
+		})
+	}
+
+	return app.Handler(), cacheService.Store()
+}
+
+func TestFindWellKnownNameBytesRelaysTag(t *testing.T) {
+	handler, store := newTestHandler(t, true)
+
+	tests := []struct {
+		name      string
+		wantEvict bool
+	}{
+		{name: "alice", wantEvict: true},
+		// response not found ไม่มี relay list จึงไม่อยู่ใน tag relays
+		{name: "bob", wantEvict: false},
+	}
+
+	for _, tt := range tests {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/.well-known/nostr.json?name=" + tt.name)
+		handler(ctx)
+		if ctx.Response.StatusCode() != fiber.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.name, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+	}
+
+	if err := store.InvalidateTag(context.Background(), tagRelays); err != nil {
+		t.Fatalf("InvalidateTag: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.GetBytes(context.Background(), fmt.Sprintf(patternKey, keyNameBytes, tt.name))
+			if evicted := errors.Is(err, cache.ErrMiss); evicted != tt.wantEvict {
+				t.Fatalf("evicted = %v (err %v), want %v", evicted, err, tt.wantEvict)
+			}
+		})
+	}
+}
+
+func BenchmarkFindWellKnownName(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		fast bool
+	}{
+		{name: "bytes", fast: true},
+		{name: "response_object", fast: false},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			handler, _ := newTestHandler(b, bm.fast)
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/.well-known/nostr.json?name=alice")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ctx.Response.Reset()
+				handler(ctx)
+				if ctx.Response.StatusCode() != fiber.StatusOK {
+					b.Fatalf("status %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package user
+
+import (
+	"context"
+	"slices"
+
+	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/cache"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+// UsersChanged แจ้งว่าข้อมูล user มีการเปลี่ยนแปลง
+// ลบ cache ของ user (name, pubkey) และ document รวม ในครั้งเดียว แล้ว export ไฟล์ใหม่
+// กรณีเปลี่ยนชื่อให้ส่งทั้งข้อมูลเก่าและใหม่
+func UsersChanged(ctx context.Context, cache cache.Service, users ...*models.User) {
+	tags := []string{tagBulk}
+	for _, u := range users {
+		tags = append(tags, userTags(u.Name, u.Pubkey)...)
+	}
+
+	err := cache.Store().InvalidateTag(ctx, tags...)
+	if err != nil {
+		logger.Log.Warnf("invalidate user cache error: %s", err)
+	}
+	TriggerExport()
+}
+
+// RelaysChanged แจ้งว่า relay list มีการเปลี่ยนแปลง
+// ลบทุก document nostr.json ที่ cache ไว้ แล้ว export ไฟล์ใหม่
+func RelaysChanged(ctx context.Context, cache cache.Service) {
+	err := cache.Store().InvalidateTag(ctx, tagRelays)
+	if err != nil {
+		logger.Log.Warnf("invalidate relays cache error: %s", err)
+	}
+	TriggerExport()
+}
+
+// WatchRelays เรียก RelaysChanged เมื่อ relay list ใน config เปลี่ยน
+func WatchRelays(cache cache.Service) {
+	relays := slices.Clone(config.CF.App.LazyRelays)
+	config.OnChange(func() {
+		if slices.Equal(relays, config.CF.App.LazyRelays) {
+			return
+		}
+		relays = slices.Clone(config.CF.App.LazyRelays)
+
+		logger.Log.Info("relay list changed, invalidating nostr.json cache")
+		RelaysChanged(context.Background(), cache)
+	})
+}
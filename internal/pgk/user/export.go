@@ -0,0 +1,144 @@
+package user
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+
+	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+var (
+	// exportDebounce รวมการเปลี่ยนแปลงที่เกิดติดกันเป็นการ export ครั้งเดียว
+	exportDebounce = 2 * time.Second
+
+	// exportDomainPlaceholder ใน output path จะถูกแทนด้วยชื่อ domain
+	exportDomainPlaceholder = "{domain}"
+
+	exportMu    sync.Mutex
+	exportTimer *time.Timer
+)
+
+// Export export nostr.json ลงไฟล์ตาม output paths
+// path ที่มี {domain} จะเขียนแยกไฟล์ต่อ domain ของ user (user ที่ไม่มี domain อยู่ในทุกไฟล์)
+// path อื่นเขียน user ทั้งหมด
+// อ่านจาก primary เพราะมักถูกเรียกทันทีหลัง user เปลี่ยน
+func Export(db *gorm.DB, paths []string) ([]string, error) {
+	var users []*models.User
+	err := NewRepository().FindAllActive(sql.Primary(db), &users)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, path := range paths {
+		if !strings.Contains(path, exportDomainPlaceholder) {
+			err := exportDocument(path, users)
+			if err != nil {
+				return written, err
+			}
+			written = append(written, path)
+			continue
+		}
+
+		byDomain := usersByDomain(users)
+		for _, domain := range slices.Sorted(maps.Keys(byDomain)) {
+			p := strings.ReplaceAll(path, exportDomainPlaceholder, domain)
+			err := exportDocument(p, byDomain[domain])
+			if err != nil {
+				return written, err
+			}
+			written = append(written, p)
+		}
+	}
+
+	return written, nil
+}
+
+// exportDocument render document ของ user แล้วเขียนลงไฟล์ เมื่อเนื้อหาเปลี่ยน
+func exportDocument(path string, users []*models.User) error {
+	b, err := json.Marshal(RenderWellKnown(users, config.CF.App.LazyRelays))
+	if err != nil {
+		return err
+	}
+
+	// ไม่เขียนไฟล์เดิมซ้ำ กัน cdn/object storage sync ไฟล์ที่ไม่ได้เปลี่ยน
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, b) {
+		return nil
+	}
+
+	return writeFileAtomic(path, b)
+}
+
+// usersByDomain แยก user ตาม domain
+// user ที่ไม่มี domain อยู่ในทุก domain, ไม่มี domain เลยจะคืน map ว่าง
+func usersByDomain(users []*models.User) map[string][]*models.User {
+	res := make(map[string][]*models.User)
+	for _, u := range users {
+		if u.Domain == "" {
+			continue
+		}
+		if !patternDomain.MatchString(u.Domain) {
+			logger.Log.Warnf("export nostr.json: skip user %s with invalid domain %q", u.Name, u.Domain)
+			continue
+		}
+		res[u.Domain] = append(res[u.Domain], u)
+	}
+
+	for _, u := range users {
+		if u.Domain != "" {
+			continue
+		}
+		for domain := range res {
+			res[domain] = append(res[domain], u)
+		}
+	}
+
+	return res
+}
+
+// TriggerExport สั่ง export ใหม่เมื่อข้อมูล user เปลี่ยน
+// ทำงานแบบ async และ debounce
+func TriggerExport() {
+	if !config.CF.Export.Enable {
+		return
+	}
+
+	exportMu.Lock()
+	defer exportMu.Unlock()
+
+	if exportTimer != nil {
+		exportTimer.Stop()
+	}
+	exportTimer = time.AfterFunc(exportDebounce, func() {
+		_, err := Export(sql.Database, config.CF.Export.OutputPaths)
+		if err != nil {
+			logger.Log.Errorf("export nostr.json error: %s", err)
+		}
+	})
+}
+
+// writeFileAtomic เขียนไฟล์ชั่วคราวก่อนแล้วค่อย rename กันไฟล์เสียระหว่างเขียน
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
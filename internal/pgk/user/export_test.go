@@ -0,0 +1,134 @@
+package user
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+
+	"github.com/saveblush/reraw-api/internal/core/config"
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+// newTestDatabase sqlite in-memory ที่ migrate แล้ว
+func newTestDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	logger.InitLogger()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open database: %s", err)
+	}
+	conn, err := db.DB()
+	if err != nil {
+		t.Fatalf("database: %s", err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = sql.MigrateUp(context.Background(), db)
+	if err != nil {
+		t.Fatalf("migrate: %s", err)
+	}
+
+	return db
+}
+
+func TestExport(t *testing.T) {
+	cf := &config.Configs{}
+	cf.App.LazyRelays = []string{"wss://relay.example.com"}
+	config.CF = cf
+
+	db := newTestDatabase(t)
+	for _, u := range []*models.User{
+		{Name: "alice", Pubkey: "a1"},
+		{Name: "bob", Pubkey: "b1", Domain: "a.example.com"},
+		{Name: "carol", Pubkey: "c1", Domain: "b.example.com"},
+		{Name: "dave", Pubkey: "d1", Domain: "a.example.com", DeletedAt: 1},
+	} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("create user: %s", err)
+		}
+	}
+
+	dir := t.TempDir()
+	all := filepath.Join(dir, "nostr.json")
+	perDomain := filepath.Join(dir, "{domain}", ".well-known", "nostr.json")
+	written, err := Export(db, []string{all, perDomain})
+	if err != nil {
+		t.Fatalf("Export: %s", err)
+	}
+
+	tests := []struct {
+		path  string
+		names map[string]string
+	}{
+		{
+			path:  all,
+			names: map[string]string{"alice": "a1", "bob": "b1", "carol": "c1"},
+		},
+		{
+			path:  filepath.Join(dir, "a.example.com", ".well-known", "nostr.json"),
+			names: map[string]string{"alice": "a1", "bob": "b1"},
+		},
+		{
+			path:  filepath.Join(dir, "b.example.com", ".well-known", "nostr.json"),
+			names: map[string]string{"alice": "a1", "carol": "c1"},
+		},
+	}
+
+	var wantWritten []string
+	for _, tt := range tests {
+		wantWritten = append(wantWritten, tt.path)
+	}
+	if !reflect.DeepEqual(written, wantWritten) {
+		t.Fatalf("written = %v, want %v", written, wantWritten)
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(filepath.Dir(filepath.Dir(tt.path))), func(t *testing.T) {
+			b, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatalf("read %s: %s", tt.path, err)
+			}
+
+			var doc WellKnownDocument
+			if err := json.Unmarshal(b, &doc); err != nil {
+				t.Fatalf("decode %s: %s", tt.path, err)
+			}
+			if !reflect.DeepEqual(doc.Names, tt.names) {
+				t.Fatalf("names = %v, want %v", doc.Names, tt.names)
+			}
+			if len(doc.Relays) != len(tt.names) {
+				t.Fatalf("relays = %v, want %d entries", doc.Relays, len(tt.names))
+			}
+		})
+	}
+}
+
+func TestExportWithoutDomains(t *testing.T) {
+	cf := &config.Configs{}
+	config.CF = cf
+
+	db := newTestDatabase(t)
+	if err := db.Create(&models.User{Name: "alice", Pubkey: "a1"}).Error; err != nil {
+		t.Fatalf("create user: %s", err)
+	}
+
+	// ไม่มี user ที่กำหนด domain จึงไม่มีไฟล์แยก domain
+	dir := t.TempDir()
+	written, err := Export(db, []string{filepath.Join(dir, "{domain}", "nostr.json")})
+	if err != nil {
+		t.Fatalf("Export: %s", err)
+	}
+	if len(written) != 0 {
+		t.Fatalf("written = %v, want none", written)
+	}
+}
@@ -9,6 +9,11 @@ import (
 // repository interface
 type Repository interface {
 	FindByIDString(db *gorm.DB, field string, value string, i interface{}) error
+	FindAllActive(db *gorm.DB, i interface{}) error
+	FindAllOrderByName(db *gorm.DB, i interface{}) error
+	Create(db *gorm.DB, i interface{}) error
+	Update(db *gorm.DB, m, i interface{}) error
+	Delete(db *gorm.DB, i interface{}) error
 }
 
 type repository struct {
@@ -20,3 +25,16 @@ func NewRepository() Repository {
 		repositories.NewRepository(),
 	}
 }
+
+// FindAllActive find all user ที่ยังไม่ถูกลบ
+func (r *repository) FindAllActive(db *gorm.DB, i interface{}) error {
+	return db.
+		Where("deleted_at IS NULL OR deleted_at = 0").
+		Where("name IS NOT NULL AND name <> ''").
+		Find(i).Error
+}
+
+// FindAllOrderByName find all user เรียงตามชื่อ
+func (r *repository) FindAllOrderByName(db *gorm.DB, i interface{}) error {
+	return db.Order("name").Find(i).Error
+}
@@ -1,10 +1,14 @@
 package user
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/goccy/go-json"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 
 	"github.com/saveblush/reraw-api/internal/core/breaker"
@@ -12,20 +16,37 @@ import (
 	"github.com/saveblush/reraw-api/internal/core/config"
 	"github.com/saveblush/reraw-api/internal/core/connection/cache"
 	"github.com/saveblush/reraw-api/internal/core/connection/client"
+	"github.com/saveblush/reraw-api/internal/core/connection/sql"
 	"github.com/saveblush/reraw-api/internal/core/generic"
-	"github.com/saveblush/reraw-api/internal/core/utils/logger"
 	"github.com/saveblush/reraw-api/internal/models"
 )
 
 var (
-	patternKey = "%s-%s"
-	keyUser    = "user"
+	patternKey   = "%s-%s"
+	keyUser      = "user"
+	keyUserStale = "user-stale"
+	keyWellKnown = "user-wellknown-all"
+	keyNameBytes = "user-wellknown-name"
+
+	// tag สำหรับลบ cache ที่เกี่ยวข้องพร้อมกัน
+	tagName   = "user-name"
+	tagPubkey = "user-pubkey"
+	tagBulk   = "user-bulk"   // document รวมทุก user
+	tagRelays = "user-relays" // ทุก document ที่มี relay list
+
+	// header บอก client ว่าข้อมูลมาจากแหล่งสำรอง
+	headerDegraded     = "X-Degraded"
+	degradedStaleCache = "stale-cache"
+	degradedSnapshot   = "snapshot"
+	degradedNone       = "unavailable"
 )
 
 // service interface
 type Service interface {
 	FindWellKnownName(c *cctx.Context, req *RequestWellKnownName) (interface{}, error)
+	FindWellKnownNameBytes(c *cctx.Context, name string) ([]byte, error)
 	FindWellKnownLNURL(c *cctx.Context, req *RequestWellKnownName) (interface{}, error)
+	ExportWellKnown(c *cctx.Context) (interface{}, error)
 }
 
 type service struct {
@@ -33,14 +54,17 @@ type service struct {
 	repository Repository
 	cache      cache.Service
 	client     client.Client
+	snapshot   *snapshot
+	group      singleflight.Group
 }
 
-func NewService() Service {
+func NewService(cache cache.Service) Service {
 	return &service{
 		config:     config.CF,
 		repository: NewRepository(),
-		cache:      cache.New(),
+		cache:      cache,
 		client:     client.New(),
+		snapshot:   newSnapshot(config.CF.Degraded.SnapshotPath),
 	}
 }
 
@@ -49,28 +73,156 @@ func (s *service) setKeyUser(d string) string {
 	return fmt.Sprintf(patternKey, keyUser, d)
 }
 
+// setKeyUserStale set key user ล่าสุดที่รู้จัก
+// เก็บนานกว่า key ปกติ ใช้ตอน db ใช้งานไม่ได้
+func (s *service) setKeyUserStale(d string) string {
+	return fmt.Sprintf(patternKey, keyUserStale, d)
+}
+
+// expire expire time พร้อมสุ่ม jitter
+func (s *service) expire(d time.Duration) time.Duration {
+	return cache.Jitter(d, s.config.Cache.ExprieTime.Jitter)
+}
+
+// userTags tag ของ cache ที่ผูกกับ user
+func userTags(name, pubkey string) []string {
+	tags := []string{fmt.Sprintf(patternKey, tagName, name)}
+	if pubkey != "" {
+		tags = append(tags, fmt.Sprintf(patternKey, tagPubkey, pubkey))
+	}
+
+	return tags
+}
+
 // getUser get user
 func (s *service) getUser(c *cctx.Context, req *RequestWellKnownName) (*models.User, error) {
 	key := s.setKeyUser(req.Name)
-	fetch := &models.User{}
 
-	// ดึงจาก cache
-	errCache := s.cache.Get(key, fetch)
+	// ดึงจาก cache (รวม name ที่ไม่มีในระบบ)
+	cached, err := cache.Get[models.User](c.Context(), s.cache.Store(), key)
+	if err == nil {
+		return &cached, nil
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		c.Logger().Warnf("get user cache error: %s", err)
+	}
 
 	// ถ้าไม่เจอ cache จะดึงจาก db แล้วเอาไปเก็บใน cache
-	if errCache != nil {
-		err := s.repository.FindByIDString(c.GetDatabase(), "name", req.Name, fetch)
+	fetch, err := s.loadUser(c, req.Name)
+	if err != nil {
+		if !sql.IsConnectionError(err) {
+			return nil, err
+		}
+
+		c.Logger().Warnf("find user error, using fallback: %s", err)
+		return s.getUserFallback(c, req), nil
+	}
+
+	return fetch, nil
+}
+
+// loadUser ดึง user จาก db แล้วเก็บใน cache
+// request ที่ miss key เดียวกันพร้อมกันจะรอผลจาก query เดียว
+func (s *service) loadUser(c *cctx.Context, name string) (*models.User, error) {
+	key := s.setKeyUser(name)
+	load := func() (interface{}, error) {
+		// ไม่ผูกกับการ cancel ของ request แรก เพราะ request อื่นรอผลอยู่
+		ctx := context.WithoutCancel(c.Context())
+		store := s.cache.Store()
+
+		// อ่านจาก primary เพราะ cache ถูกล้างทันทีหลังเขียน
+		// ถ้าอ่านจาก replica ที่ยังตามไม่ทันจะเก็บข้อมูลเก่าไว้ใน cache
+		fetch := &models.User{}
+		err := s.repository.FindByIDString(sql.Primary(c.GetDatabase().WithContext(ctx)), "name", name, fetch)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
 		}
 
-		// เก็บใน cache
+		// เก็บใน cache (key ปกติและ key สำรองใน round trip เดียว)
+		tags := userTags(name, fetch.Pubkey)
 		if !generic.IsEmpty(fetch) {
-			_ = s.cache.Set(key, fetch, s.config.Cache.ExprieTime.UserInfo)
+			data, err := json.Marshal(fetch)
+			if err == nil {
+				_ = store.MSetBytes(ctx,
+					cache.Item{Key: key, Value: data, TTL: s.expire(s.config.Cache.ExprieTime.UserInfo), Tags: tags},
+					cache.Item{Key: s.setKeyUserStale(name), Value: data, TTL: s.config.Degraded.StaleTTL, Tags: tags},
+				)
+			}
+		} else {
+			if ttl := s.config.Cache.ExprieTime.NotFound; ttl > 0 {
+				_ = cache.SetWithTags(ctx, store, key, fetch, s.expire(ttl), tags...)
+			}
+			_ = store.Delete(ctx, s.setKeyUserStale(name))
 		}
+
+		return fetch, nil
 	}
 
-	return fetch, nil
+	if !s.config.Cache.ExprieTime.Coalesce {
+		v, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return v.(*models.User), nil
+	}
+
+	v, err, _ := s.group.Do(key, load)
+	if err != nil {
+		return nil, err
+	}
+
+	// copy เพื่อไม่ให้แต่ละ request ใช้ pointer ร่วมกัน
+	fetch := *v.(*models.User)
+	return &fetch, nil
+}
+
+// getUserFallback get user กรณี db ใช้งานไม่ได้
+// ดึงจาก cache ล่าสุดที่รู้จัก (แม้หมดอายุ) ถ้าไม่เจอจะดึงจาก snapshot
+func (s *service) getUserFallback(c *cctx.Context, req *RequestWellKnownName) *models.User {
+	fetch, err := cache.Get[models.User](c.Context(), s.cache.Store(), s.setKeyUserStale(req.Name))
+	if err == nil && !generic.IsEmpty(fetch) {
+		c.Set(headerDegraded, degradedStaleCache)
+		return &fetch
+	}
+
+	user, err := s.snapshot.Find(req.Name)
+	if err != nil {
+		c.Logger().Warnf("read user snapshot error: %s", err)
+	}
+	if user != nil {
+		c.Set(headerDegraded, degradedSnapshot)
+		return user
+	}
+
+	c.Set(headerDegraded, degradedNone)
+	return &models.User{}
+}
+
+// getWellKnownAll get document nostr.json ของ user ทั้งหมด
+// สร้างไว้ล่วงหน้าและเก็บใน cache, คืนค่า nil เมื่อจำนวน user เกิน limit
+func (s *service) getWellKnownAll(c *cctx.Context) (*WellKnownDocument, error) {
+	store := s.cache.Store()
+	cached, err := cache.Get[WellKnownDocument](c.Context(), store, keyWellKnown)
+	if err == nil {
+		return &cached, nil
+	}
+
+	// อ่านจาก primary เหมือน loadUser
+	var users []*models.User
+	err = s.repository.FindAllActive(sql.Primary(c.GetDatabase()), &users)
+	if err != nil {
+		return nil, err
+	}
+
+	if max := s.config.WellKnown.Bulk.MaxUsers; max > 0 && len(users) > max {
+		c.Logger().Warnf("bulk nostr.json disabled: %d users exceeds limit %d", len(users), max)
+		return nil, nil
+	}
+
+	doc := RenderWellKnown(users, s.config.App.LazyRelays)
+	_ = cache.SetWithTags(c.Context(), store, keyWellKnown, doc, s.expire(s.config.Cache.ExprieTime.WellKnown), tagBulk, tagRelays)
+
+	return doc, nil
 }
 
 // FindWellKnownName find well known name nostr username
@@ -80,16 +232,22 @@ func (s *service) FindWellKnownName(c *cctx.Context, req *RequestWellKnownName)
 	}
 
 	if generic.IsEmpty(req.Name) {
+		// NIP-05 ไม่ส่ง name = ขอรายชื่อทั้งหมด
+		if s.config.WellKnown.Bulk.Enable {
+			doc, err := s.getWellKnownAll(c)
+			if err != nil {
+				return nil, err
+			}
+			if doc != nil {
+				return doc, nil
+			}
+		}
+
 		res := resNotfound
 		res["message"] = "field validation for 'name'"
 		return res, nil
 	}
 
-	var relays []string
-	if !generic.IsEmpty(s.config.App.LazyRelays) {
-		relays = s.config.App.LazyRelays
-	}
-
 	fetch, err := s.getUser(c, req)
 	if err != nil {
 		return nil, err
@@ -101,16 +259,57 @@ func (s *service) FindWellKnownName(c *cctx.Context, req *RequestWellKnownName)
 		return res, nil
 	}
 
-	res := map[string]interface{}{
-		"names": map[string]interface{}{
-			fetch.Name: fetch.Pubkey,
-		},
-		"relays": map[string]interface{}{
-			fetch.Pubkey: relays,
-		},
+	return RenderWellKnown([]*models.User{fetch}, s.config.App.LazyRelays), nil
+}
+
+// ExportWellKnown export nostr.json ลงไฟล์
+func (s *service) ExportWellKnown(c *cctx.Context) (interface{}, error) {
+	paths, err := Export(c.GetDatabase(), s.config.Export.OutputPaths)
+	if err != nil {
+		c.Logger().Errorf("export nostr.json error: %s", err)
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"paths": paths,
+	}, nil
+}
+
+// FindWellKnownNameBytes find well known name แบบ fast path
+// cache response json ที่ render แล้วต่อ name ไม่ต้อง decode/encode ซ้ำ
+func (s *service) FindWellKnownNameBytes(c *cctx.Context, name string) ([]byte, error) {
+	store := s.cache.Store()
+	key := fmt.Sprintf(patternKey, keyNameBytes, name)
+	if !generic.IsEmpty(name) {
+		if b, err := store.GetBytes(c.Context(), key); err == nil {
+			return b, nil
+		}
 	}
 
-	return res, nil
+	res, err := s.FindWellKnownName(c, &RequestWellKnownName{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+
+	// ไม่ cache ข้อมูลจากแหล่งสำรอง
+	// name ที่ไม่มีในระบบ cache ตาม NOT_FOUND
+	// tag relays เฉพาะ document ที่เจอ response not found ไม่มี relay list
+	ttl := s.config.Cache.ExprieTime.NotFound
+	tags := userTags(name, "")
+	if doc, found := res.(*WellKnownDocument); found {
+		ttl = s.config.Cache.ExprieTime.UserInfo
+		tags = append(userTags(name, doc.Names[name]), tagRelays)
+	}
+	if !generic.IsEmpty(name) && generic.IsEmpty(c.GetRespHeader(headerDegraded)) && ttl > 0 {
+		_ = store.SetWithTags(c.Context(), key, b, s.expire(ttl), tags...)
+	}
+
+	return b, nil
 }
 
 func (s *service) FindWellKnownLNURL(c *cctx.Context, req *RequestWellKnownName) (interface{}, error) {
@@ -144,9 +343,9 @@ func (s *service) FindWellKnownLNURL(c *cctx.Context, req *RequestWellKnownName)
 
 	var res map[string]interface{}
 	url := fmt.Sprintf("https://%s/.well-known/lnurlp/%s", lnDomain[1], lnDomain[0])
-	_, err = s.client.Get(url, nil, nil, &res, breaker.BreakerName)
+	_, err = s.client.WithContext(c.Context()).Get(url, nil, nil, &res, breaker.BreakerName)
 	if err != nil {
-		logger.Log.Errorf("get lnurl error: %s", err)
+		c.Logger().Errorf("get lnurl error: %s", err)
 		return nil, err
 	}
 
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+// snapshot snapshot ข้อมูล user ที่ใช้งานอยู่บน disk
+// ใช้ตอบ nostr.json กรณี db ใช้งานไม่ได้
+type snapshot struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	users   map[string]*models.User
+}
+
+func newSnapshot(path string) *snapshot {
+	return &snapshot{
+		path: path,
+	}
+}
+
+// Find find user by name
+// โหลดไฟล์ใหม่เมื่อไฟล์มีการเปลี่ยนแปลง
+func (s *snapshot) Find(name string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.users == nil || !info.ModTime().Equal(s.modTime) {
+		b, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, err
+		}
+
+		var users []*models.User
+		if err := json.Unmarshal(b, &users); err != nil {
+			return nil, err
+		}
+
+		s.users = make(map[string]*models.User, len(users))
+		for _, u := range users {
+			s.users[u.Name] = u
+		}
+		s.modTime = info.ModTime()
+	}
+
+	return s.users[name], nil
+}
+
+// WriteSnapshot write snapshot user ที่ใช้งานอยู่ทั้งหมดลงไฟล์
+func WriteSnapshot(db *gorm.DB, path string) error {
+	var users []*models.User
+	err := NewRepository().FindAllActive(db, &users)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(users)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, b)
+}
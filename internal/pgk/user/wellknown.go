@@ -0,0 +1,37 @@
+package user
+
+import (
+	"github.com/saveblush/reraw-api/internal/core/generic"
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+// WellKnownDocument document nostr.json (NIP-05)
+type WellKnownDocument struct {
+	Names  map[string]string   `json:"names"`
+	Relays map[string][]string `json:"relays"`
+}
+
+// RenderWellKnown render document nostr.json จากรายการ user
+// ใช้ร่วมกันทั้ง endpoint และการ export ไฟล์
+// user ที่ไม่ได้กำหนด relay list จะใช้ relays (relay list กลาง)
+func RenderWellKnown(users []*models.User, relays []string) *WellKnownDocument {
+	doc := &WellKnownDocument{
+		Names:  make(map[string]string, len(users)),
+		Relays: make(map[string][]string, len(users)),
+	}
+
+	for _, u := range users {
+		if generic.IsEmpty(u.Name) || generic.IsEmpty(u.Pubkey) {
+			continue
+		}
+
+		doc.Names[u.Name] = u.Pubkey
+		if len(u.Relays) > 0 {
+			doc.Relays[u.Pubkey] = u.Relays
+		} else {
+			doc.Relays[u.Pubkey] = relays
+		}
+	}
+
+	return doc
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saveblush/reraw-api/internal/models"
+)
+
+func TestRenderWellKnown(t *testing.T) {
+	global := []string{"wss://relay.example.com"}
+	own := models.Relays{"wss://alice.example.com", "wss://nos.lol"}
+
+	tests := []struct {
+		name  string
+		users []*models.User
+		want  *WellKnownDocument
+	}{
+		{
+			name:  "no users",
+			users: nil,
+			want:  &WellKnownDocument{Names: map[string]string{}, Relays: map[string][]string{}},
+		},
+		{
+			name: "global relays",
+			users: []*models.User{
+				{Name: "alice", Pubkey: "a1"},
+			},
+			want: &WellKnownDocument{
+				Names:  map[string]string{"alice": "a1"},
+				Relays: map[string][]string{"a1": global},
+			},
+		},
+		{
+			name: "own relays",
+			users: []*models.User{
+				{Name: "alice", Pubkey: "a1", Relays: own},
+				{Name: "bob", Pubkey: "b1"},
+			},
+			want: &WellKnownDocument{
+				Names:  map[string]string{"alice": "a1", "bob": "b1"},
+				Relays: map[string][]string{"a1": own, "b1": global},
+			},
+		},
+		{
+			name: "skip incomplete users",
+			users: []*models.User{
+				{Name: "alice"},
+				{Pubkey: "b1"},
+			},
+			want: &WellKnownDocument{Names: map[string]string{}, Relays: map[string][]string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderWellKnown(tt.users, global)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("RenderWellKnown = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
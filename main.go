@@ -1,11 +1,14 @@
 package main
 
 import (
-	"flag"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 
@@ -14,31 +17,48 @@ import (
 	"github.com/saveblush/reraw-api/internal/core/config"
 	"github.com/saveblush/reraw-api/internal/core/connection/cache"
 	"github.com/saveblush/reraw-api/internal/core/connection/sql"
+	"github.com/saveblush/reraw-api/internal/core/jobs"
+	"github.com/saveblush/reraw-api/internal/core/lifecycle"
+	"github.com/saveblush/reraw-api/internal/core/metrics"
+	"github.com/saveblush/reraw-api/internal/core/tracing"
 	"github.com/saveblush/reraw-api/internal/core/utils/logger"
+	"github.com/saveblush/reraw-api/internal/core/utils/retry"
+	"github.com/saveblush/reraw-api/internal/handlers/middlewares"
 	"github.com/saveblush/reraw-api/internal/handlers/routes"
+	"github.com/saveblush/reraw-api/internal/pgk/user"
+)
+
+var (
+	defaultDrainTimeout   = 30 * time.Second
+	defaultCleanupTimeout = 10 * time.Second
+
+	defaultReconnectInterval = 5 * time.Second
+
+	jobUserSnapshot = "user-snapshot"
+	jobUserExport   = "user-export"
+
+	// errMetricsPortPrefork prefork แต่ละ child เก็บ metrics ของตัวเอง จึงเปิด port แยกไม่ได้
+	errMetricsPortPrefork = errors.New("METRICS.PORT: separate metrics port is not supported with HTTP_SERVER.PREFORK, use PORT 0")
 )
 
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
 func main() {
-	flag.Parse()
-
 	// Init logger
 	logger.InitLogger()
 
-	// Init configuration
-	err := config.InitConfig()
-	if err != nil {
-		logger.Log.Panicf("init configuration error: %s", err)
-	}
-
-	// Init return result
-	err = config.InitReturnResult()
+	err := newRootCommand().Execute()
 	if err != nil {
-		logger.Log.Panicf("init return result error: %s", err)
+		logger.Log.Error(err)
+		_ = logger.Sync()
+		os.Exit(1)
 	}
+}
 
+// serve run http server จนกว่าจะได้รับ signal
+// addr ว่าง = ใช้ port จาก config
+func serve(addr string) error {
 	// Set swagger info
 	docs.SwaggerInfo.Title = config.CF.Swagger.Title
 	docs.SwaggerInfo.Description = config.CF.Swagger.Description
@@ -46,37 +66,66 @@ func main() {
 	docs.SwaggerInfo.Host = fmt.Sprintf("%s%s", config.CF.Swagger.Host, config.CF.Swagger.BaseURL)
 	//docs.SwaggerInfo.Schemes = []string{"https", "http"}
 
+	// Init tracing
+	err := initTracing()
+	if err != nil {
+		return fmt.Errorf("init tracing error: %w", err)
+	}
+
 	// Init database
 	err = initDatabase()
 	if err != nil {
-		logger.Log.Panicf("init database error: %s", err)
+		return fmt.Errorf("init database error: %w", err)
+	}
+
+	// Auto migrate
+	err = initMigration()
+	if err != nil {
+		return fmt.Errorf("init migration error: %w", err)
 	}
 
 	// Init cache
-	err = initCache()
+	cacheService, err := initCache()
 	if err != nil {
-		logger.Log.Panicf("init cache error: %s", err)
+		return fmt.Errorf("init cache error: %w", err)
 	}
 
+	// ลบ cache nostr.json เมื่อ relay list เปลี่ยน
+	user.WatchRelays(cacheService)
+
 	// Init Circuit Breaker
 	breaker.Init()
 
+	// Init metrics
+	err = initMetrics(cacheService)
+	if err != nil {
+		return fmt.Errorf("init metrics error: %w", err)
+	}
+
+	// Init background jobs
+	err = initJobs(cacheService)
+	if err != nil {
+		return fmt.Errorf("init jobs error: %w", err)
+	}
+
 	// New app
-	app, err := routes.NewServer()
+	app, err := routes.NewServer(cacheService)
 	if err != nil {
-		logger.Log.Panicf("new server error: %s", err)
+		return fmt.Errorf("new server error: %w", err)
 	}
 
 	// Init router
 	app.InitRouter()
 
 	// Listen app
-	addr := flag.String("addr", fmt.Sprintf(":%d", config.CF.App.Port), "http service address")
+	if addr == "" {
+		addr = fmt.Sprintf(":%d", config.CF.App.Port)
+	}
 	listenConfig := fiber.ListenConfig{
 		EnablePrefork: config.CF.HTTPServer.Prefork,
 	}
 	go func() {
-		err = app.Listen(*addr, listenConfig)
+		err := app.Listen(addr, listenConfig)
 		if err != nil {
 			logger.Log.Panicf("server start error: %s", err)
 		}
@@ -87,21 +136,58 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 	logger.Log.Info("Gracefully shutting down...")
+	shutdown(app, cacheService)
+
+	return nil
+}
+
+// shutdown graceful shutdown
+// ปิดระบบเป็นลำดับ readiness -> listener -> worker -> connection
+func shutdown(app routes.Server, cacheService cache.Service) {
+	cf := config.CF.HTTPServer.Shutdown
+	drainTimeout := cf.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+	cleanupTimeout := cf.CleanupTimeout
+	if cleanupTimeout <= 0 {
+		cleanupTimeout = defaultCleanupTimeout
+	}
+
+	// Phase 1: readiness fail
+	lifecycle.BeginShutdown()
+	logger.Log.Infof("[shutdown 1/5] readiness set to failing, waiting %s", cf.PreStopDelay)
+	time.Sleep(cf.PreStopDelay)
 
-	// Close app
-	_ = app.Close()
+	// Phase 2: stop listener and drain in-flight requests
+	logger.Log.Infof("[shutdown 2/5] stopping listener, draining %d in-flight requests (timeout %s)", middlewares.InFlightRequests(), drainTimeout)
+	err := app.Close(drainTimeout)
+	if err != nil {
+		logger.Log.Errorf("server shutdown error: %s, %d requests still in flight", err, middlewares.InFlightRequests())
+	}
 	logger.Log.Info("Server closed")
-	logger.Log.Info("Running cleanup tasks...")
 
-	// Close cache
-	_ = cache.New().Close()
+	// Phase 3: stop background workers and watchers
+	logger.Log.Info("[shutdown 3/5] stopping background workers")
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	lifecycle.Stop(ctx)
+
+	// Phase 4: close connection pools
+	logger.Log.Info("[shutdown 4/5] closing connections")
+	_ = cacheService.Close()
 	logger.Log.Info("Cache connection closed")
 
-	// Close db
 	_ = closeDatabase()
 	logger.Log.Info("Database connection closed")
 
+	// Phase 5: flush telemetry
+	logger.Log.Info("[shutdown 5/5] flushing telemetry")
+	_ = tracing.Close()
+	logger.Log.Info("Tracing provider closed")
+
 	logger.Log.Info("App was successful shutdown")
+	_ = logger.Sync()
 }
 
 // initDatabase init connection database
@@ -117,10 +203,33 @@ func initDatabase() error {
 		MaxIdleConns: config.CF.Database.RelaySQL.MaxIdleConns,
 		MaxOpenConns: config.CF.Database.RelaySQL.MaxOpenConns,
 		MaxLifetime:  config.CF.Database.RelaySQL.MaxLifetime,
+		Retry:        startupRetryPolicy(),
+
+		ReplicaCheckInterval: config.CF.Database.RelaySQL.ReplicaCheckInterval,
+	}
+	for _, replica := range config.CF.Database.RelaySQL.Replicas {
+		configuration.Replicas = append(configuration.Replicas, sql.Replica{
+			Host:     replica.Host,
+			Port:     replica.Port,
+			Username: replica.Username,
+			Password: replica.Password,
+		})
 	}
 	session, err := sql.InitConnection(configuration)
 	if err != nil {
-		return err
+		if !config.CF.Startup.Degraded {
+			return err
+		}
+
+		// degraded mode เปิด pool ไว้ก่อน แล้วรอ db พร้อม
+		logger.Log.Warnf("database unavailable, starting in degraded mode: %s", err)
+		session, err = sql.Open(configuration)
+		if err != nil {
+			return err
+		}
+		go waitForDependency("database", func(ctx context.Context) error {
+			return sql.Ping(ctx, session.Database)
+		})
 	}
 	sql.Database = session.Database
 
@@ -132,15 +241,137 @@ func initDatabase() error {
 	return nil
 }
 
-// initCache init cache
-func initCache() error {
+// initMigration migrate up ตอนเริ่มระบบ เมื่อเปิด AUTO_MIGRATE
+// degraded mode ที่ db ยังไม่พร้อมจะข้ามไปก่อน
+func initMigration() error {
+	if !config.CF.Database.AutoMigrate || fiber.IsChild() {
+		return nil
+	}
+
+	_, err := sql.MigrateUp(context.Background(), sql.Database)
+	if err != nil && config.CF.Startup.Degraded && sql.IsConnectionError(err) {
+		logger.Log.Warnf("database unavailable, skipping auto migration: %s", err)
+		return nil
+	}
+
+	return err
+}
+
+// initCache init cache ตาม driver
+func initCache() (cache.Service, error) {
+	cf := config.CF.Cache.Redis
 	configuration := &cache.Configuration{
-		Host:     config.CF.Cache.Redis.Host,
-		Port:     config.CF.Cache.Redis.Port,
-		Password: config.CF.Cache.Redis.Password,
-		DB:       config.CF.Cache.Redis.DB,
+		Driver:           config.CF.Cache.Driver,
+		Size:             config.CF.Cache.Memory.Size,
+		Retry:            startupRetryPolicy(),
+		Mode:             cf.Mode,
+		Host:             cf.Host,
+		Port:             cf.Port,
+		Addrs:            cf.Addrs,
+		MasterName:       cf.MasterName,
+		Username:         cf.Username,
+		Password:         cf.Password,
+		SentinelUsername: cf.SentinelUsername,
+		SentinelPassword: cf.SentinelPassword,
+		DB:               cf.DB,
+		PoolSize:         cf.PoolSize,
+		MinIdleConns:     cf.MinIdleConns,
+		DialTimeout:      cf.DialTimeout,
+		ReadTimeout:      cf.ReadTimeout,
+		WriteTimeout:     cf.WriteTimeout,
+		KeyPrefix:        cf.KeyPrefix,
+		TLS: cache.TLSConfiguration{
+			Enable:             cf.TLS.Enable,
+			CAFile:             cf.TLS.CAFile,
+			CertFile:           cf.TLS.CertFile,
+			KeyFile:            cf.TLS.KeyFile,
+			ServerName:         cf.TLS.ServerName,
+			InsecureSkipVerify: cf.TLS.InsecureSkipVerify,
+		},
+	}
+	cacheService, err := cache.Init(configuration)
+	if err != nil {
+		if !config.CF.Startup.Degraded {
+			return nil, err
+		}
+
+		// degraded mode เปิด pool ไว้ก่อน แล้วรอ cache พร้อม
+		logger.Log.Warnf("cache unavailable, starting in degraded mode: %s", err)
+		cacheService, err = cache.Open(configuration)
+		if err != nil {
+			return nil, err
+		}
+		go waitForDependency("cache", cacheService.Ping)
+	}
+
+	// local cache หน้า redis
+	if config.CF.Cache.Local.Enable {
+		layered, stop, err := cache.NewLocal(cacheService, &cache.LocalConfiguration{
+			Size:    config.CF.Cache.Local.Size,
+			TTL:     config.CF.Cache.Local.TTL,
+			Channel: config.CF.Cache.Local.Channel,
+		})
+		if err != nil {
+			return nil, err
+		}
+		lifecycle.OnStop("local cache subscriber", stop)
+		cacheService = layered
+	}
+
+	return cacheService, nil
+}
+
+// startupRetryPolicy retry policy ตอนเริ่มระบบ
+func startupRetryPolicy() retry.Policy {
+	return retry.Policy{
+		Attempts:   config.CF.Startup.Retries,
+		Backoff:    config.CF.Startup.Backoff,
+		MaxBackoff: config.CF.Startup.MaxBackoff,
+	}
+}
+
+// waitForDependency ping dependency เป็นระยะจนกว่าจะพร้อม
+// ระหว่างนี้ readiness จะแสดงสถานะ fail
+func waitForDependency(name string, ping func(ctx context.Context) error) {
+	interval := config.CF.Startup.ReconnectInterval
+	if interval <= 0 {
+		interval = defaultReconnectInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if lifecycle.Stopping() {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := ping(ctx)
+		cancel()
+		if err == nil {
+			logger.Log.Infof("%s is available, leaving degraded mode", name)
+			return
+		}
+		logger.Log.Warnf("%s still unavailable: %s", name, err)
+	}
+}
+
+// initLogger init logger from configuration
+func initLogger() error {
+	configuration := &logger.Configuration{
+		Level:        config.CF.Log.Level,
+		Encoding:     config.CF.Log.Encoding,
+		Outputs:      config.CF.Log.Outputs,
+		ErrorOutputs: config.CF.Log.ErrorOutputs,
+		Rotation: logger.Rotation{
+			MaxSize:    config.CF.Log.Rotation.MaxSize,
+			MaxBackups: config.CF.Log.Rotation.MaxBackups,
+			MaxAge:     config.CF.Log.Rotation.MaxAge,
+			Compress:   config.CF.Log.Rotation.Compress,
+		},
 	}
-	err := cache.Init(configuration)
+	err := logger.Configure(configuration)
 	if err != nil {
 		return err
 	}
@@ -148,6 +379,136 @@ func initCache() error {
 	return nil
 }
 
+// initTracing init tracing
+func initTracing() error {
+	if !config.CF.Tracing.Enable {
+		return nil
+	}
+
+	configuration := &tracing.Configuration{
+		ServiceName:    config.CF.App.ProjectName,
+		ServiceVersion: config.CF.App.Version,
+		Environment:    string(config.CF.App.Environment),
+		Exporter:       config.CF.Tracing.Exporter,
+		Endpoint:       config.CF.Tracing.Endpoint,
+		Insecure:       config.CF.Tracing.Insecure,
+		FilePath:       config.CF.Tracing.FilePath,
+		SampleRatio:    config.CF.Tracing.SampleRatio,
+	}
+	err := tracing.Init(configuration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// initMetrics init metrics
+func initMetrics(cacheService cache.Service) error {
+	if !config.CF.Metrics.Enable {
+		return nil
+	}
+
+	db, err := sql.Database.DB()
+	if err != nil {
+		return err
+	}
+
+	err = metrics.RegisterDatabase(db, config.CF.Database.RelaySQL.DatabaseName)
+	if err != nil {
+		return err
+	}
+
+	if pooler, ok := cacheService.Store().(cache.Pooler); ok {
+		err = metrics.RegisterRedis(pooler.PoolStats)
+		if err != nil {
+			return err
+		}
+	}
+
+	// แยก port สำหรับ metrics
+	// prefork ใช้ไม่ได้ เพราะ process หลักไม่ได้รับ request จึงมีแต่ค่า 0 (ดู checkConfig)
+	if config.CF.Metrics.Port > 0 {
+		if config.CF.HTTPServer.Prefork {
+			return errMetricsPortPrefork
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(config.CF.Metrics.Path, metrics.Handler())
+		srv := &http.Server{
+			Addr:    fmt.Sprintf(":%d", config.CF.Metrics.Port),
+			Handler: mux,
+		}
+		go func() {
+			err := srv.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				logger.Log.Errorf("metrics server error: %s", err)
+			}
+		}()
+		lifecycle.OnStop("metrics server", srv.Shutdown)
+	}
+
+	return nil
+}
+
+// initJobs init background jobs
+// prefork child ลงทะเบียน job ไว้สั่งผ่าน admin ได้ แต่ไม่ทำตามรอบ
+// job ตอนนี้เขียนไฟล์ลง disk ของแต่ละ instance จึงเป็น local ทั้งหมด
+// job ที่แก้ข้อมูลกลาง (db/cache) ให้ใช้ Local: false จะทำที่ leader ครั้งเดียวผ่าน lock
+func initJobs(cacheService cache.Service) error {
+	jobs.Init(cacheService.Store(), &jobs.Configuration{
+		LockTTL:     config.CF.Jobs.LockTTL,
+		ElectionTTL: config.CF.Jobs.ElectionTTL,
+	})
+
+	// เขียน snapshot user ลง disk ของแต่ละ instance
+	// ใช้ตอบ nostr.json กรณี db ใช้งานไม่ได้
+	interval := config.CF.Degraded.SnapshotInterval
+	if interval > 0 {
+		err := jobs.Register(&jobs.Job{
+			Name:     jobUserSnapshot,
+			Schedule: fmt.Sprintf("@every %s", interval),
+			Local:    true,
+			Run: func(ctx context.Context) error {
+				return user.WriteSnapshot(sql.Database.WithContext(ctx), config.CF.Degraded.SnapshotPath)
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// export nostr.json ตามรอบ ข้อมูลที่แก้ผ่าน api/command จะ export ทันทีผ่าน TriggerExport อยู่แล้ว
+	exportInterval := config.CF.Export.Interval
+	if config.CF.Export.Enable && exportInterval > 0 {
+		err := jobs.Register(&jobs.Job{
+			Name:     jobUserExport,
+			Schedule: fmt.Sprintf("@every %s", exportInterval),
+			Local:    true,
+			Run: func(ctx context.Context) error {
+				_, err := user.Export(sql.Database.WithContext(ctx), config.CF.Export.OutputPaths)
+				return err
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	lifecycle.OnStop("job scheduler", jobs.Stop)
+	if fiber.IsChild() {
+		return nil
+	}
+
+	jobs.Start()
+	if interval > 0 {
+		_ = jobs.Trigger(jobUserSnapshot)
+	}
+	user.TriggerExport()
+
+	return nil
+}
+
 // closeDatabase close connection database
 func closeDatabase() error {
 	sql.CloseConnection(sql.Database)